@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"fandom/notifications/internal/config"
+	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+	"fandom/notifications/internal/service"
+)
+
+func main() {
+	var name, role string
+	flag.StringVar(&name, "name", "Admin", "Name for the admin")
+	flag.StringVar(&role, "role", models.AdminRoleOwner, "Role: "+strings.Join(models.AdminRoles, ", "))
+	flag.Parse()
+
+	if !slices.Contains(models.AdminRoles, role) {
+		log.Fatalf("Unknown role %q, expected one of: %s", role, strings.Join(models.AdminRoles, ", "))
+	}
+
+	cfg := config.Load()
+
+	ctx := context.Background()
+
+	// Connect to database
+	db, err := database.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(ctx); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	adminService := service.NewAdminService(repository.NewAdminRepository(db), 0)
+
+	response, err := adminService.CreateAdmin(ctx, name, role)
+	if err != nil {
+		log.Fatalf("Failed to create admin: %v", err)
+	}
+
+	fmt.Printf("Admin created successfully!\n\n")
+	fmt.Printf("Name: %s\n", response.Name)
+	fmt.Printf("Role: %s\n", response.Role)
+	fmt.Printf("Key:  %s\n", response.Key)
+	fmt.Printf("Prefix: %s\n", response.Prefix)
+	fmt.Println()
+	fmt.Println("⚠️  IMPORTANT: Save this key securely. It cannot be retrieved later.")
+	fmt.Printf("\nUse it for the admin API and the dashboard:\n")
+	fmt.Printf("  curl -H \"Authorization: Bearer %s\" http://localhost:8080/admins/me\n", response.Key)
+}
@@ -5,18 +5,31 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"fandom/notifications/internal/config"
 	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/models"
 	"fandom/notifications/internal/repository"
 	"fandom/notifications/internal/service"
 )
 
 func main() {
-	var name string
+	var name, scopeList string
+	var expiresIn time.Duration
 	flag.StringVar(&name, "name", "Initial API Key", "Name for the API key")
+	flag.StringVar(&scopeList, "scopes", "", "Comma-separated scopes (default: "+strings.Join(models.DefaultAPIKeyScopes, ",")+")")
+	flag.DurationVar(&expiresIn, "expires-in", 0, "Expire the key after this long, e.g. 720h (default: never)")
 	flag.Parse()
 
+	var scopes []string
+	for _, scope := range strings.Split(scopeList, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+
 	cfg := config.Load()
 
 	ctx := context.Background()
@@ -30,10 +43,16 @@ func main() {
 
 	// Initialize services
 	apiKeyRepo := repository.NewAPIKeyRepository(db)
-	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, 0)
 
 	// Generate API key
-	response, err := apiKeyService.CreateAPIKey(ctx, name)
+	var expiresAt *time.Time
+	if expiresIn > 0 {
+		t := time.Now().Add(expiresIn)
+		expiresAt = &t
+	}
+
+	response, err := apiKeyService.CreateAPIKey(ctx, name, scopes, expiresAt)
 	if err != nil {
 		log.Fatalf("Failed to create API key: %v", err)
 	}
@@ -41,7 +60,13 @@ func main() {
 	fmt.Printf("API Key generated successfully!\n\n")
 	fmt.Printf("Name: %s\n", response.Name)
 	fmt.Printf("Key:  %s\n", response.Key)
-	fmt.Printf("Created at: %s\n\n", response.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Prefix: %s\n", response.Prefix)
+	fmt.Printf("Scopes: %s\n", strings.Join(response.Scopes, ", "))
+	fmt.Printf("Created at: %s\n", response.CreatedAt.Format("2006-01-02 15:04:05"))
+	if response.ExpiresAt != nil {
+		fmt.Printf("Expires at: %s\n", response.ExpiresAt.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Println()
 	fmt.Println("⚠️  IMPORTANT: Save this key securely. It cannot be retrieved later.")
 	fmt.Printf("\nYou can use it like this:\n")
 	fmt.Printf("  curl \"http://localhost:8080/hello?api=%s\"\n", response.Key)
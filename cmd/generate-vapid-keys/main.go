@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"fandom/notifications/internal/webpush"
+)
+
+func main() {
+	keys, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		log.Fatalf("Failed to generate VAPID keys: %v", err)
+	}
+
+	fmt.Printf("VAPID keys generated successfully!\n\n")
+	fmt.Printf("VAPID_PUBLIC_KEY=%s\n", keys.PublicKey)
+	fmt.Printf("VAPID_PRIVATE_KEY=%s\n\n", keys.PrivateKey)
+	fmt.Println("⚠️  IMPORTANT: Keep the private key secret. Changing the keys invalidates every existing push subscription.")
+}
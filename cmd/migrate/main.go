@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"fandom/notifications/internal/config"
+	"fandom/notifications/internal/database"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  status        List migrations and whether they are applied
+  up            Apply every pending migration
+  down [steps]  Revert the last applied migration, or the last steps ones
+  to <version>  Apply or revert migrations until the schema is at version (0 reverts everything)
+`
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+
+	ctx := context.Background()
+
+	// Connect to database
+	db, err := database.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	switch flag.Arg(0) {
+	case "status":
+		printStatus(ctx, migrator)
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		fmt.Printf("Applied %d migration(s)\n", count)
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			steps, err = strconv.Atoi(flag.Arg(1))
+			if err != nil || steps < 1 {
+				log.Fatalf("steps must be a positive number, got %q", flag.Arg(1))
+			}
+		}
+		count, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		fmt.Printf("Reverted %d migration(s)\n", count)
+	case "to":
+		if flag.NArg() < 2 {
+			log.Fatalf("to needs a version, e.g. migrate to 3")
+		}
+		version, err := strconv.ParseInt(flag.Arg(1), 10, 64)
+		if err != nil || version < 0 {
+			log.Fatalf("version must be a number, got %q", flag.Arg(1))
+		}
+		count, err := migrator.To(ctx, version)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		fmt.Printf("Applied or reverted %d migration(s); schema is at version %d\n", count, version)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func printStatus(ctx context.Context, migrator *database.Migrator) {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		log.Fatalf("Failed to read migration status: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+
+		note := ""
+		switch {
+		case status.Missing:
+			note = "applied, but no migration file in this build"
+		case status.Modified:
+			note = "file changed since it was applied"
+		}
+
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, appliedAt, note)
+	}
+	w.Flush()
+}
@@ -12,7 +12,12 @@ import (
 	_ "fandom/notifications/docs"
 	"fandom/notifications/internal/config"
 	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/mailer"
+	"fandom/notifications/internal/realtime"
+	"fandom/notifications/internal/repository"
 	"fandom/notifications/internal/server"
+	"fandom/notifications/internal/service"
+	"fandom/notifications/internal/webpush"
 )
 
 // @title           Notifications API
@@ -20,6 +25,21 @@ import (
 // @description     Simple API built with Gin and Swagger.
 // @BasePath        /
 // @schemes         http
+//
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 API key as "Bearer <key>". Checked first.
+//
+// @securityDefinitions.apikey  APIKeyHeader
+// @in                          header
+// @name                        X-API-Key
+// @description                 API key in the X-API-Key header. Checked after Authorization.
+//
+// @securityDefinitions.apikey  APIKeyQuery
+// @in                          query
+// @name                        api
+// @description                 API key as the 'api' query parameter. Checked last, after the session cookie set by /auth. Prefer a header: query strings end up in proxy and request logs.
 func main() {
 	ctx := context.Background()
 	cfg := config.Load()
@@ -36,13 +56,59 @@ func main() {
 		log.Fatalf("failed to run migrations: %v", err)
 	}
 
-	router := server.NewRouter(cfg, db)
+	// In-process fan-out for real-time notification streams
+	hub := realtime.NewHub()
+
+	// Admin accounts; MASTER_API_KEY becomes the first owner on a fresh database.
+	// Admin keys are cached and their last_used_at batched like API keys
+	adminService := service.NewAdminService(repository.NewAdminRepository(db), cfg.APIKeyCacheTTL)
+	if created, err := adminService.Bootstrap(ctx, cfg.MasterAPIKey); err != nil {
+		log.Fatalf("failed to bootstrap admin: %v", err)
+	} else if created {
+		log.Println("Created owner admin from MASTER_API_KEY")
+	}
+	adminService.Start()
+
+	// API key validation with a short-lived cache and batched last_used_at writes
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db), cfg.APIKeyCacheTTL)
+	apiKeyService.Start()
+
+	// Request logs are buffered and written in batches
+	logRepo := repository.NewLogRepository(db)
+	logWriter := service.NewLogWriter(logRepo, cfg.RequestLogBufferSize, cfg.RequestLogBatchSize, cfg.RequestLogFlushInterval)
+	logWriter.Start()
+
+	// Upcoming request_logs partitions, and retention for old ones
+	logPartitions := service.NewLogPartitionManager(logRepo, cfg.RequestLogPartitionInterval, cfg.RequestLogRetention)
+	logPartitions.Start()
+
+	// Durable background jobs; channels register their queues before Start
+	jobQueue := service.NewJobQueue(repository.NewJobRepository(db))
+
+	// Web Push delivery, only when VAPID keys are configured
+	var pushClient *webpush.Client
+	if cfg.VAPIDPublicKey != "" && cfg.VAPIDPrivateKey != "" {
+		vapidKeys, err := webpush.ParseVAPIDKeys(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey)
+		if err != nil {
+			log.Fatalf("invalid VAPID keys: %v", err)
+		}
+		pushClient = webpush.NewClient(vapidKeys, cfg.VAPIDSubject)
+	} else {
+		log.Println("VAPID keys not set, web push is disabled")
+	}
+	pushService := service.NewPushService(repository.NewPushRepository(db), pushClient, jobQueue)
+	jobQueue.Start()
+
+	router := server.NewRouter(cfg, db, hub, adminService, apiKeyService, logWriter, logPartitions, pushService)
 
 	httpServer := &http.Server{
 		Addr:    ":" + cfg.Port,
 		Handler: router,
 	}
 
+	// End open notification streams so Shutdown doesn't wait on them
+	httpServer.RegisterOnShutdown(hub.Close)
+
 	go func() {
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("server error: %v", err)
@@ -51,6 +117,25 @@ func main() {
 
 	log.Printf("Server starting on port %s", cfg.Port)
 
+	// Background delivery of queued webhooks
+	webhookDispatcher := service.NewWebhookDispatcher(repository.NewWebhookRepository(db), nil, cfg.WebhookMaxAttempts)
+	webhookDispatcher.Start()
+
+	// Daily email digests, only when an SMTP server is configured
+	var digestScheduler *service.DigestScheduler
+	if cfg.SMTPHost != "" {
+		sender := mailer.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword)
+		digestScheduler = service.NewDigestScheduler(
+			repository.NewDigestRepository(db),
+			repository.NewNotificationRepository(db),
+			sender,
+			cfg.SMTPFrom,
+		)
+		digestScheduler.Start()
+	} else {
+		log.Println("SMTP_HOST not set, email digests are disabled")
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -64,5 +149,35 @@ func main() {
 		log.Fatalf("server shutdown failed: %v", err)
 	}
 
+	if err := logWriter.Stop(shutdownCtx); err != nil {
+		log.Printf("request logs were not flushed: %v", err)
+	}
+
+	if err := logPartitions.Stop(shutdownCtx); err != nil {
+		log.Printf("request log partition maintenance did not stop cleanly: %v", err)
+	}
+
+	if err := apiKeyService.Stop(shutdownCtx); err != nil {
+		log.Printf("api key last-used times were not flushed: %v", err)
+	}
+
+	if err := adminService.Stop(shutdownCtx); err != nil {
+		log.Printf("admin last-used times were not flushed: %v", err)
+	}
+
+	if err := webhookDispatcher.Stop(shutdownCtx); err != nil {
+		log.Printf("webhook dispatcher did not stop cleanly: %v", err)
+	}
+
+	if digestScheduler != nil {
+		if err := digestScheduler.Stop(shutdownCtx); err != nil {
+			log.Printf("digest scheduler did not stop cleanly: %v", err)
+		}
+	}
+
+	if err := jobQueue.Stop(shutdownCtx); err != nil {
+		log.Printf("job queue did not drain cleanly: %v", err)
+	}
+
 	log.Println("Server exited")
 }
@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -11,7 +14,63 @@ type Config struct {
 	GinMode      string
 	DatabaseURL  string
 	DatabaseName string
+	// MasterAPIKey becomes the key of the first owner admin when the admins
+	// table is empty; it is ignored afterwards.
 	MasterAPIKey string
+	// APIKeyRotationGrace is how long a rotated API key keeps working unless
+	// the rotate request says otherwise.
+	APIKeyRotationGrace time.Duration
+	// APIKeyCacheTTL is how long a validated API or admin key is cached;
+	// revocations made on other instances take up to this long to apply. 0
+	// disables it.
+	APIKeyCacheTTL time.Duration
+	// SessionTTL is how long a dashboard login lasts.
+	SessionTTL time.Duration
+	// SessionCookieSecure marks the session cookie Secure. Browsers accept
+	// Secure cookies on http://localhost, so only disable it when serving
+	// plain HTTP on another host.
+	SessionCookieSecure bool
+	// RateLimitBackend is "memory" (per instance) or "postgres" (shared by
+	// all instances).
+	RateLimitBackend string
+	// RateLimitPerMinute and RateLimitBurst are the default token bucket for
+	// keys without their own limit and for unauthenticated clients. A rate of
+	// 0 disables the default limit.
+	RateLimitPerMinute int
+	RateLimitBurst     int
+	// AuthFailuresPerMinute and AuthFailuresBurst limit failed
+	// authentication per client IP; once used up, requests from the IP are
+	// rejected before their key is checked. A rate of 0 disables the limit.
+	AuthFailuresPerMinute int
+	AuthFailuresBurst     int
+	// Request logs are buffered in memory, up to RequestLogBufferSize
+	// entries, and written RequestLogBatchSize at a time or every
+	// RequestLogFlushInterval. Entries are dropped while the buffer is full.
+	RequestLogBufferSize    int
+	RequestLogBatchSize     int
+	RequestLogFlushInterval time.Duration
+	// RequestLogPartitionInterval is "day" or "month": how much time each
+	// request_logs partition covers.
+	RequestLogPartitionInterval string
+	// RequestLogRetention is how long request logs are kept; whole
+	// partitions are dropped once they are older. 0 keeps them forever.
+	RequestLogRetention time.Duration
+	// WebSocketAllowedOrigins lists extra browser origins allowed to open
+	// WebSocket connections. Same-origin requests are always allowed.
+	WebSocketAllowedOrigins []string
+	// WebhookMaxAttempts is how many times a webhook delivery is tried
+	// before it is moved to the dead state.
+	WebhookMaxAttempts int
+	// SMTP settings for email digests. Digests are disabled when SMTPHost is empty.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	// VAPID keys for Web Push. Push delivery is disabled when they are empty.
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	VAPIDSubject    string
 }
 
 func Load() Config {
@@ -58,11 +117,141 @@ func Load() Config {
 
 	masterAPIKey := os.Getenv("MASTER_API_KEY")
 
+	apiKeyRotationGrace, err := time.ParseDuration(os.Getenv("API_KEY_ROTATION_GRACE"))
+	if err != nil || apiKeyRotationGrace < 0 {
+		apiKeyRotationGrace = 24 * time.Hour
+	}
+
+	sessionTTL, err := time.ParseDuration(os.Getenv("SESSION_TTL"))
+	if err != nil || sessionTTL <= 0 {
+		sessionTTL = 7 * 24 * time.Hour
+	}
+
+	sessionCookieSecure, err := strconv.ParseBool(os.Getenv("SESSION_COOKIE_SECURE"))
+	if err != nil {
+		sessionCookieSecure = true
+	}
+
+	rateLimitBackend := os.Getenv("RATE_LIMIT_BACKEND")
+	if rateLimitBackend == "" {
+		rateLimitBackend = "memory"
+	}
+
+	rateLimitPerMinute, err := strconv.Atoi(os.Getenv("RATE_LIMIT_PER_MINUTE"))
+	if err != nil || rateLimitPerMinute < 0 {
+		rateLimitPerMinute = 300
+	}
+
+	rateLimitBurst, err := strconv.Atoi(os.Getenv("RATE_LIMIT_BURST"))
+	if err != nil || rateLimitBurst <= 0 {
+		rateLimitBurst = 60
+	}
+
+	authFailuresPerMinute, err := strconv.Atoi(os.Getenv("RATE_LIMIT_AUTH_FAILURES_PER_MINUTE"))
+	if err != nil || authFailuresPerMinute < 0 {
+		authFailuresPerMinute = 10
+	}
+
+	authFailuresBurst, err := strconv.Atoi(os.Getenv("RATE_LIMIT_AUTH_FAILURES_BURST"))
+	if err != nil || authFailuresBurst <= 0 {
+		authFailuresBurst = 20
+	}
+
+	apiKeyCacheTTL, err := time.ParseDuration(os.Getenv("API_KEY_CACHE_TTL"))
+	if err != nil || apiKeyCacheTTL < 0 {
+		apiKeyCacheTTL = 30 * time.Second
+	}
+
+	requestLogBufferSize, err := strconv.Atoi(os.Getenv("REQUEST_LOG_BUFFER_SIZE"))
+	if err != nil || requestLogBufferSize <= 0 {
+		requestLogBufferSize = 10000
+	}
+
+	requestLogBatchSize, err := strconv.Atoi(os.Getenv("REQUEST_LOG_BATCH_SIZE"))
+	if err != nil || requestLogBatchSize <= 0 {
+		requestLogBatchSize = 500
+	}
+
+	requestLogFlushInterval, err := time.ParseDuration(os.Getenv("REQUEST_LOG_FLUSH_INTERVAL"))
+	if err != nil || requestLogFlushInterval <= 0 {
+		requestLogFlushInterval = time.Second
+	}
+
+	requestLogPartitionInterval := os.Getenv("REQUEST_LOG_PARTITION_INTERVAL")
+	if requestLogPartitionInterval == "" {
+		requestLogPartitionInterval = "day"
+	}
+
+	requestLogRetention, err := time.ParseDuration(os.Getenv("REQUEST_LOG_RETENTION"))
+	if err != nil || requestLogRetention < 0 {
+		requestLogRetention = 90 * 24 * time.Hour
+	}
+
+	var wsAllowedOrigins []string
+	for _, origin := range strings.Split(os.Getenv("WS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			wsAllowedOrigins = append(wsAllowedOrigins, origin)
+		}
+	}
+
+	webhookMaxAttempts, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	if err != nil || webhookMaxAttempts <= 0 {
+		webhookMaxAttempts = 8
+	}
+
+	smtpPort, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil || smtpPort <= 0 {
+		smtpPort = 587
+	}
+
+	smtpFrom := os.Getenv("SMTP_FROM")
+	if smtpFrom == "" {
+		smtpFrom = "Notifications <notifications@localhost>"
+	}
+
+	vapidSubject := os.Getenv("VAPID_SUBJECT")
+	if vapidSubject == "" {
+		vapidSubject = "mailto:notifications@localhost"
+	}
+
 	return Config{
 		Port:         port,
 		GinMode:      ginMode,
 		DatabaseURL:  databaseURL,
 		DatabaseName: databaseName,
 		MasterAPIKey: masterAPIKey,
+
+		APIKeyRotationGrace: apiKeyRotationGrace,
+		APIKeyCacheTTL:      apiKeyCacheTTL,
+
+		SessionTTL:          sessionTTL,
+		SessionCookieSecure: sessionCookieSecure,
+
+		RateLimitBackend:   rateLimitBackend,
+		RateLimitPerMinute: rateLimitPerMinute,
+		RateLimitBurst:     rateLimitBurst,
+
+		AuthFailuresPerMinute: authFailuresPerMinute,
+		AuthFailuresBurst:     authFailuresBurst,
+
+		RequestLogBufferSize:    requestLogBufferSize,
+		RequestLogBatchSize:     requestLogBatchSize,
+		RequestLogFlushInterval: requestLogFlushInterval,
+
+		RequestLogPartitionInterval: requestLogPartitionInterval,
+		RequestLogRetention:         requestLogRetention,
+
+		WebSocketAllowedOrigins: wsAllowedOrigins,
+		WebhookMaxAttempts:      webhookMaxAttempts,
+
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     smtpPort,
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:     smtpFrom,
+
+		VAPIDPublicKey:  os.Getenv("VAPID_PUBLIC_KEY"),
+		VAPIDPrivateKey: os.Getenv("VAPID_PRIVATE_KEY"),
+		VAPIDSubject:    vapidSubject,
 	}
 }
@@ -0,0 +1,15 @@
+-- Drops every table and all of its data.
+
+DROP TABLE IF EXISTS request_logs;
+DROP TABLE IF EXISTS rate_limit_buckets;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS admins;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS digest_preferences;
+DROP TABLE IF EXISTS push_subscriptions;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS bookmarks;
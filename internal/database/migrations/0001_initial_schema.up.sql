@@ -0,0 +1,225 @@
+-- Schema as created by the single startup script that preceded versioned
+-- migrations. Every statement is idempotent, so databases created by that
+-- script are adopted as they are.
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+	id SERIAL PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	publication_id VARCHAR(255) NOT NULL,
+	chapter_id VARCHAR(255) NOT NULL,
+	image VARCHAR(255),
+	chapter VARCHAR(255),
+	volume VARCHAR(255),
+	name VARCHAR(255),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_id ON bookmarks(user_id);
+CREATE INDEX IF NOT EXISTS idx_bookmarks_publication_id ON bookmarks(publication_id);
+
+-- One reading position per user per publication: keep the most recently updated row.
+-- Old rows may have no updated_at (or created_at); a NULL would make the
+-- comparison NULL and leave both duplicates in place.
+DELETE FROM bookmarks b
+USING bookmarks newer
+WHERE b.user_id = newer.user_id
+	AND b.publication_id = newer.publication_id
+	AND (COALESCE(b.updated_at, b.created_at, '-infinity'), b.id)
+		< (COALESCE(newer.updated_at, newer.created_at, '-infinity'), newer.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_user_publication ON bookmarks(user_id, publication_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+	id BIGSERIAL PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	type VARCHAR(50) NOT NULL,
+	publication_id VARCHAR(255) NOT NULL,
+	chapter_id VARCHAR(255) NOT NULL,
+	chapter VARCHAR(255),
+	volume VARCHAR(255),
+	name VARCHAR(255),
+	image VARCHAR(255),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_dedupe ON notifications(user_id, type, publication_id, chapter_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, id DESC);
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS read_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+	id SERIAL PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	endpoint TEXT NOT NULL UNIQUE,
+	p256dh VARCHAR(255) NOT NULL,
+	auth VARCHAR(255) NOT NULL,
+	user_agent TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	last_used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_subscriptions_user_id ON push_subscriptions(user_id);
+
+CREATE TABLE IF NOT EXISTS digest_preferences (
+	user_id VARCHAR(255) PRIMARY KEY,
+	email VARCHAR(320) NOT NULL,
+	enabled BOOLEAN NOT NULL DEFAULT TRUE,
+	send_hour SMALLINT NOT NULL DEFAULT 8,
+	timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+	last_sent_at TIMESTAMPTZ,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+	id SERIAL PRIMARY KEY,
+	key_hash VARCHAR(64) UNIQUE,
+	key_prefix VARCHAR(16),
+	name VARCHAR(255),
+	active BOOLEAN DEFAULT TRUE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	last_used_at TIMESTAMP
+);
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS key_hash VARCHAR(64) UNIQUE;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS key_prefix VARCHAR(16);
+
+-- Keys used to be stored in plaintext in api_keys.key. Hash them in place
+-- and drop the column; clients keep using the same keys.
+DO $$
+BEGIN
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'api_keys' AND column_name = 'key'
+	) THEN
+		UPDATE api_keys
+		SET key_hash = encode(sha256(convert_to(key, 'UTF8')), 'hex'),
+			key_prefix = left(key, 8)
+		WHERE key_hash IS NULL;
+
+		ALTER TABLE api_keys DROP COLUMN key;
+	END IF;
+END $$;
+
+ALTER TABLE api_keys ALTER COLUMN key_hash SET NOT NULL;
+
+-- Keys that predate scopes keep the access every key used to have
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT ARRAY[
+	'bookmarks:read', 'bookmarks:write',
+	'notifications:read', 'notifications:write',
+	'webhooks:read', 'webhooks:write',
+	'preferences:read', 'preferences:write'
+]::TEXT[];
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS replaced_by_id INTEGER REFERENCES api_keys(id) ON DELETE SET NULL;
+
+-- NULL falls back to RATE_LIMIT_PER_MINUTE / RATE_LIMIT_BURST
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS rate_limit_per_minute INTEGER;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS rate_limit_burst INTEGER;
+CREATE INDEX IF NOT EXISTS idx_api_keys_active ON api_keys(active);
+
+CREATE TABLE IF NOT EXISTS admins (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	key_hash VARCHAR(64) NOT NULL UNIQUE,
+	key_prefix VARCHAR(16),
+	role VARCHAR(20) NOT NULL,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	last_used_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+	id BIGSERIAL PRIMARY KEY,
+	token_hash VARCHAR(64) NOT NULL UNIQUE,
+	admin_id INTEGER REFERENCES admins(id) ON DELETE CASCADE,
+	api_key_id INTEGER REFERENCES api_keys(id) ON DELETE CASCADE,
+	user_agent TEXT,
+	ip_address VARCHAR(45),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	expires_at TIMESTAMPTZ NOT NULL,
+	CHECK ((admin_id IS NULL) <> (api_key_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+	id SERIAL PRIMARY KEY,
+	api_key_id INTEGER NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+	url VARCHAR(2048) NOT NULL,
+	secret VARCHAR(255) NOT NULL,
+	event_types TEXT[] NOT NULL,
+	active BOOLEAN DEFAULT TRUE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_api_key_id ON webhook_subscriptions(api_key_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id BIGSERIAL PRIMARY KEY,
+	subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+	event_type VARCHAR(100) NOT NULL,
+	payload JSONB NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_error TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	delivered_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id ON webhook_deliveries(subscription_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+	id BIGSERIAL PRIMARY KEY,
+	delivery_id BIGINT NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+	attempt INTEGER NOT NULL,
+	status_code INTEGER,
+	error TEXT,
+	duration_ms BIGINT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery_id ON webhook_delivery_attempts(delivery_id);
+
+CREATE TABLE IF NOT EXISTS jobs (
+	id BIGSERIAL PRIMARY KEY,
+	queue VARCHAR(100) NOT NULL,
+	payload JSONB NOT NULL,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	max_attempts INTEGER NOT NULL DEFAULT 5,
+	run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_error TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs(queue, run_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+	key VARCHAR(255) PRIMARY KEY,
+	full_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS request_logs (
+	id SERIAL PRIMARY KEY,
+	method VARCHAR(10) NOT NULL,
+	path VARCHAR(500) NOT NULL,
+	query_params TEXT,
+	status_code INTEGER NOT NULL,
+	ip_address VARCHAR(45),
+	user_agent TEXT,
+	api_key VARCHAR(255),
+	response_time_ms BIGINT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_request_logs_created_at ON request_logs(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_request_logs_method ON request_logs(method);
+CREATE INDEX IF NOT EXISTS idx_request_logs_status_code ON request_logs(status_code);
+CREATE INDEX IF NOT EXISTS idx_request_logs_path ON request_logs(path);
+CREATE INDEX IF NOT EXISTS idx_request_logs_api_key ON request_logs(api_key);
@@ -0,0 +1,38 @@
+-- Copies every partition back into a single table.
+
+ALTER TABLE request_logs RENAME TO request_logs_partitioned;
+ALTER TABLE request_logs_partitioned RENAME CONSTRAINT request_logs_pkey TO request_logs_partitioned_pkey;
+
+DROP INDEX IF EXISTS idx_request_logs_created_at;
+DROP INDEX IF EXISTS idx_request_logs_method;
+DROP INDEX IF EXISTS idx_request_logs_status_code;
+DROP INDEX IF EXISTS idx_request_logs_path;
+DROP INDEX IF EXISTS idx_request_logs_api_key;
+
+CREATE TABLE request_logs (
+	id BIGINT PRIMARY KEY DEFAULT nextval('request_logs_id_seq'),
+	method VARCHAR(10) NOT NULL,
+	path VARCHAR(500) NOT NULL,
+	query_params TEXT,
+	status_code INTEGER NOT NULL,
+	ip_address VARCHAR(45),
+	user_agent TEXT,
+	api_key VARCHAR(255),
+	response_time_ms BIGINT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER SEQUENCE request_logs_id_seq OWNED BY request_logs.id;
+
+INSERT INTO request_logs (id, method, path, query_params, status_code, ip_address, user_agent, api_key, response_time_ms, created_at)
+SELECT id, method, path, query_params, status_code, ip_address, user_agent, api_key, response_time_ms, created_at
+FROM request_logs_partitioned;
+
+-- Drops the partitions with it
+DROP TABLE request_logs_partitioned;
+
+CREATE INDEX idx_request_logs_created_at ON request_logs(created_at DESC);
+CREATE INDEX idx_request_logs_method ON request_logs(method);
+CREATE INDEX idx_request_logs_status_code ON request_logs(status_code);
+CREATE INDEX idx_request_logs_path ON request_logs(path);
+CREATE INDEX idx_request_logs_api_key ON request_logs(api_key);
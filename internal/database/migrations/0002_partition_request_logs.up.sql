@@ -0,0 +1,75 @@
+-- Partition request_logs by created_at so old logs can be dropped a
+-- partition at a time and queries by date only read the partitions they
+-- need. Existing rows are copied into monthly partitions up to today; the
+-- server creates today's partition onwards and drops those older than
+-- REQUEST_LOG_RETENTION. Rows outside every partition land in
+-- request_logs_default.
+--
+-- created_at holds UTC from now on and partitions are UTC days or months.
+-- Existing rows keep the server's local time they were written in.
+
+ALTER TABLE request_logs RENAME TO request_logs_unpartitioned;
+ALTER TABLE request_logs_unpartitioned RENAME CONSTRAINT request_logs_pkey TO request_logs_unpartitioned_pkey;
+
+DROP INDEX IF EXISTS idx_request_logs_created_at;
+DROP INDEX IF EXISTS idx_request_logs_method;
+DROP INDEX IF EXISTS idx_request_logs_status_code;
+DROP INDEX IF EXISTS idx_request_logs_path;
+DROP INDEX IF EXISTS idx_request_logs_api_key;
+
+-- The primary key of a partitioned table must include the partition key
+CREATE TABLE request_logs (
+	id BIGINT NOT NULL DEFAULT nextval('request_logs_id_seq'),
+	method VARCHAR(10) NOT NULL,
+	path VARCHAR(500) NOT NULL,
+	query_params TEXT,
+	status_code INTEGER NOT NULL,
+	ip_address VARCHAR(45),
+	user_agent TEXT,
+	api_key VARCHAR(255),
+	response_time_ms BIGINT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT (CURRENT_TIMESTAMP AT TIME ZONE 'UTC'),
+	PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+ALTER SEQUENCE request_logs_id_seq AS BIGINT OWNED BY request_logs.id;
+
+CREATE TABLE request_logs_default PARTITION OF request_logs DEFAULT;
+
+CREATE INDEX idx_request_logs_created_at ON request_logs(created_at DESC);
+CREATE INDEX idx_request_logs_method ON request_logs(method);
+CREATE INDEX idx_request_logs_status_code ON request_logs(status_code);
+CREATE INDEX idx_request_logs_path ON request_logs(path);
+CREATE INDEX idx_request_logs_api_key ON request_logs(api_key);
+
+-- Partitions are named after the first day they hold
+DO $$
+DECLARE
+	month_start TIMESTAMP;
+	today TIMESTAMP := date_trunc('day', CURRENT_TIMESTAMP AT TIME ZONE 'UTC');
+BEGIN
+	SELECT date_trunc('month', MIN(created_at)) INTO month_start FROM request_logs_unpartitioned;
+
+	WHILE month_start IS NOT NULL AND month_start < today LOOP
+		EXECUTE format(
+			'CREATE TABLE %I PARTITION OF request_logs FOR VALUES FROM (%L) TO (%L)',
+			'request_logs_p' || to_char(month_start, 'YYYYMMDD'),
+			month_start,
+			LEAST(month_start + INTERVAL '1 month', today)
+		);
+		month_start := month_start + INTERVAL '1 month';
+	END LOOP;
+
+	EXECUTE format(
+		'CREATE TABLE %I PARTITION OF request_logs FOR VALUES FROM (%L) TO (%L)',
+		'request_logs_p' || to_char(today, 'YYYYMMDD'),
+		today,
+		today + INTERVAL '1 day'
+	);
+END $$;
+
+INSERT INTO request_logs (id, method, path, query_params, status_code, ip_address, user_agent, api_key, response_time_ms, created_at)
+SELECT id, method, path, query_params, status_code, ip_address, user_agent, api_key, response_time_ms, COALESCE(created_at, CURRENT_TIMESTAMP AT TIME ZONE 'UTC')
+FROM request_logs_unpartitioned;
+
+DROP TABLE request_logs_unpartitioned;
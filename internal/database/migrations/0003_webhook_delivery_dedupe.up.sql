@@ -0,0 +1,8 @@
+-- Deliveries created with a dedupe key are created at most once per
+-- subscription and event type, so replayed events do not fan out again.
+
+ALTER TABLE webhook_deliveries ADD COLUMN dedupe_key VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_webhook_deliveries_dedupe
+	ON webhook_deliveries(subscription_id, event_type, dedupe_key)
+	WHERE dedupe_key IS NOT NULL;
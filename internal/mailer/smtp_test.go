@@ -0,0 +1,264 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts a single SMTP session and records the envelope and
+// message it receives.
+type fakeSMTPServer struct {
+	listener net.Listener
+	done     chan struct{}
+
+	auth string
+	from string
+	rcpt []string
+	data []byte
+	err  error
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTPServer{listener: listener, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		s.err = err
+		return
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	text := textproto.NewConn(conn)
+	reply := func(line string) { _ = text.PrintfLine("%s", line) }
+
+	reply("220 localhost fake ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			s.err = err
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			s.auth = arg
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.from = arg
+			reply("250 2.1.0 OK")
+		case "RCPT":
+			s.rcpt = append(s.rcpt, arg)
+			reply("250 2.1.5 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			s.data, s.err = text.ReadDotBytes()
+			if s.err != nil {
+				return
+			}
+			reply("250 2.0.0 Queued")
+		case "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			reply("502 5.5.2 Command not recognized")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) wait(t *testing.T) {
+	t.Helper()
+
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP session did not finish")
+	}
+	if s.err != nil {
+		t.Fatalf("SMTP server: %v", s.err)
+	}
+}
+
+func TestSMTPSenderSendsMultipartAlternative(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	// Long lines and non-ASCII text exercise the quoted-printable encoding
+	textBody := "Neue Kapitel für dich: Solo Leveling 180 " + strings.Repeat("und mehr ", 20) + "\n"
+	htmlBody := "<p>Neue Kapitel für dich: <b>Solo Leveling</b> 180</p>\n"
+	msg := Message{
+		From:     "Fandom <digest@fandom.example>",
+		To:       []string{"reader@example.com", "Second Reader <second@example.com>"},
+		Subject:  "Deine Zusammenfassung – 3 neue Kapitel",
+		TextBody: textBody,
+		HTMLBody: htmlBody,
+	}
+
+	sender := NewSMTPSender("127.0.0.1", server.port(), "digest", "secret")
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	server.wait(t)
+
+	wantAuth := "PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00digest\x00secret"))
+	if server.auth != wantAuth {
+		t.Errorf("AUTH %q, want %q", server.auth, wantAuth)
+	}
+	if server.from != "FROM:<digest@fandom.example>" {
+		t.Errorf("MAIL %q", server.from)
+	}
+	if want := []string{"TO:<reader@example.com>", "TO:<second@example.com>"}; strings.Join(server.rcpt, ",") != strings.Join(want, ",") {
+		t.Errorf("RCPT %q, want %q", server.rcpt, want)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(server.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+
+	if got := parsed.Header.Get("From"); got != msg.From {
+		t.Errorf("From = %q, want %q", got, msg.From)
+	}
+	if got := parsed.Header.Get("To"); got != "reader@example.com, Second Reader <second@example.com>" {
+		t.Errorf("To = %q", got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Subject = %q (%v), want %q", subject, err, msg.Subject)
+	}
+	if raw := parsed.Header.Get("Subject"); !isASCII(raw) {
+		t.Errorf("Subject header is not encoded: %q", raw)
+	}
+	if got := parsed.Header.Get("MIME-Version"); got != "1.0" {
+		t.Errorf("MIME-Version = %q", got)
+	}
+	if got := parsed.Header.Get("Message-ID"); !strings.HasSuffix(got, "@fandom.example>") {
+		t.Errorf("Message-ID = %q, want one at the sender's domain", got)
+	}
+	if _, err := parsed.Header.Date(); err != nil {
+		t.Errorf("Date: %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v), want multipart/alternative", parsed.Header.Get("Content-Type"), err)
+	}
+
+	wantParts := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", textBody},
+		{"text/html; charset=utf-8", htmlBody},
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for i, want := range wantParts {
+		// NextRawPart leaves the transfer encoding alone so it can be checked
+		part, err := reader.NextRawPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		if got := part.Header.Get("Content-Type"); got != want.contentType {
+			t.Errorf("part %d Content-Type = %q, want %q", i, got, want.contentType)
+		}
+		if got := part.Header.Get("Content-Transfer-Encoding"); got != "quoted-printable" {
+			t.Errorf("part %d Content-Transfer-Encoding = %q", i, got)
+		}
+
+		raw, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		// ReadDotBytes turned CRLF line endings into LF
+		for _, line := range strings.Split(string(raw), "\n") {
+			if len(line) > 76 || !isASCII(line) {
+				t.Errorf("part %d has a line that is not valid quoted-printable output: %q", i, line)
+			}
+		}
+
+		decoded, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(raw)))
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		if string(decoded) != want.body {
+			t.Errorf("part %d body = %q, want %q", i, decoded, want.body)
+		}
+	}
+	if _, err := reader.NextRawPart(); err != io.EOF {
+		t.Errorf("expected exactly two parts, got error %v", err)
+	}
+}
+
+func TestSMTPSenderSendsPlainText(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	msg := Message{
+		From:     "digest@fandom.example",
+		To:       []string{"reader@example.com"},
+		Subject:  "Your digest",
+		TextBody: "One new chapter.\n",
+	}
+
+	// Without a username no AUTH command is sent
+	if err := NewSMTPSender("127.0.0.1", server.port(), "", "").Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	server.wait(t)
+
+	if server.auth != "" {
+		t.Errorf("unexpected AUTH %q", server.auth)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(server.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	if got := parsed.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := parsed.Header.Get("Content-Transfer-Encoding"); got != "quoted-printable" {
+		t.Errorf("Content-Transfer-Encoding = %q", got)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	if err != nil || string(body) != msg.TextBody {
+		t.Errorf("body = %q (%v), want %q", body, err, msg.TextBody)
+	}
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
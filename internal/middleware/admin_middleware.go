@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/service"
+)
+
+// AdminContextKey is the gin context key holding the *models.Admin resolved
+// by AdminAuth or AdminOrAPIKeyAuth.
+const AdminContextKey = "admin"
+
+// AdminAuth accepts admin keys only. Every admin passes; use RequireRole on
+// routes that need more than viewer access.
+func AdminAuth(adminService *service.AdminService, sessionService *service.SessionService) gin.HandlerFunc {
+	return keyAuth(adminService, nil, sessionService, "admin key", true)
+}
+
+// RequireRole rejects requests not made by an admin with role or a more
+// privileged one.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		admin := CurrentAdmin(c)
+		if admin == nil || !admin.HasRole(role) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Requires the '" + role + "' admin role",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CurrentAdmin returns the admin resolved by AdminAuth or AdminOrAPIKeyAuth,
+// or nil if the request was not made by an admin.
+func CurrentAdmin(c *gin.Context) *models.Admin {
+	if v, ok := c.Get(AdminContextKey); ok {
+		if admin, ok := v.(*models.Admin); ok {
+			return admin
+		}
+	}
+	return nil
+}
+
+// abortWithoutKey redirects browsers to the auth page and rejects API
+// clients with message.
+func abortWithoutKey(c *gin.Context, message string) {
+	MarkAuthFailed(c)
+	if c.GetHeader("Accept") == "text/html" || c.Request.URL.Path == "/dashboard" {
+		c.Redirect(http.StatusFound, "/auth")
+		c.Abort()
+		return
+	}
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": message,
+	})
+	c.Abort()
+}
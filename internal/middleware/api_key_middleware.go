@@ -1,46 +1,25 @@
 package middleware
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
+	"fandom/notifications/internal/models"
 	"fandom/notifications/internal/service"
 )
 
-func APIKeyAuth(apiKeyService *service.APIKeyService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Try to get API key from cookie first, then fall back to query parameter
-		apiKey, err := c.Cookie("api_key")
-		if err != nil || apiKey == "" {
-			apiKey = c.Query("api")
-		}
-
-		if apiKey == "" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "API key is required. Provide it as 'api' query parameter or authenticate via /auth page.",
-			})
-			c.Abort()
-			return
-		}
+// APIKeyContextKey is the gin context key holding the *models.APIKey resolved by APIKeyAuth.
+const APIKeyContextKey = "api_key"
 
-		valid, err := apiKeyService.ValidateKey(c.Request.Context(), apiKey)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Internal server error",
-			})
-			c.Abort()
-			return
-		}
+func APIKeyAuth(apiKeyService *service.APIKeyService, sessionService *service.SessionService) gin.HandlerFunc {
+	return keyAuth(nil, apiKeyService, sessionService, "API key", false)
+}
 
-		if !valid {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Invalid or inactive API key",
-			})
-			c.Abort()
-			return
+// CurrentAPIKey returns the API key resolved by APIKeyAuth, or nil outside of it.
+func CurrentAPIKey(c *gin.Context) *models.APIKey {
+	if v, ok := c.Get(APIKeyContextKey); ok {
+		if key, ok := v.(*models.APIKey); ok {
+			return key
 		}
-
-		c.Next()
 	}
+	return nil
 }
 
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/service"
+)
+
+// APIKeyHeader is the header clients can send their API key in.
+const APIKeyHeader = "X-API-Key"
+
+// SessionCookieName is the cookie holding the session token set by /auth/set.
+const SessionCookieName = "session"
+
+// apiKeyRequiredMessage tells clients where to put their key.
+const apiKeyRequiredMessage = "Provide it as 'Authorization: Bearer <key>', in the X-API-Key header, as 'api' query parameter, or authenticate via /auth page."
+
+// Requests are authenticated by the first of these that is present:
+//
+//  1. Authorization: Bearer <key>
+//  2. X-API-Key header
+//  3. a valid session cookie (set by /auth)
+//  4. api query parameter
+//
+// Headers come first because query strings leak into proxy and request logs;
+// the query parameter remains for clients that cannot set headers, such as
+// browser EventSource and WebSocket outside a session.
+
+// ExtractAPIKey returns the raw key the client sent in a header or the query
+// string, or "" if there is none. Session cookies are resolved separately by
+// the auth middleware, after headers and before the query string.
+func ExtractAPIKey(c *gin.Context) string {
+	if key := headerAPIKey(c); key != "" {
+		return key
+	}
+	return c.Query("api")
+}
+
+func headerAPIKey(c *gin.Context) string {
+	if key := bearerToken(c.GetHeader("Authorization")); key != "" {
+		return key
+	}
+	return strings.TrimSpace(c.GetHeader(APIKeyHeader))
+}
+
+// bearerToken returns the token of a "Bearer" Authorization header. Other
+// schemes are ignored.
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// sessionPrincipal returns the admin or API key behind the session cookie.
+// It returns neither when a header carries a key, when there is no cookie,
+// or when the session is no longer valid, so the caller falls back to the
+// query string. Resolved sessions are remembered for CSRFProtection.
+func sessionPrincipal(c *gin.Context, sessionService *service.SessionService) (*models.Admin, *models.APIKey, error) {
+	if headerAPIKey(c) != "" {
+		return nil, nil, nil
+	}
+
+	token, err := c.Cookie(SessionCookieName)
+	if err != nil || token == "" {
+		return nil, nil, nil
+	}
+
+	admin, apiKey, err := sessionService.Resolve(c.Request.Context(), token)
+	if err == nil && (admin != nil || apiKey != nil) {
+		c.Set(sessionTokenContextKey, token)
+	}
+	return admin, apiKey, err
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/service"
+)
+
+var (
+	// errKeyRequired is returned by authenticate when the request carries
+	// no key and no session.
+	errKeyRequired = errors.New("key is required")
+	// errInvalidKey is returned by authenticate when the key matches no
+	// principal the middleware accepts.
+	errInvalidKey = errors.New("invalid or inactive key")
+)
+
+// keyAuth authenticates requests with authenticate and stores the admin or
+// API key under AdminContextKey or APIKeyContextKey. keyName ("admin key",
+// "API key") names the accepted keys in error messages; with redirect set, browsers without a key
+// are sent to the auth page.
+func keyAuth(admins *service.AdminService, apiKeys *service.APIKeyService, sessions *service.SessionService, keyName string, redirect bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		admin, key, err := authenticate(c, admins, apiKeys, sessions)
+		if err != nil {
+			abortUnauthenticated(c, err, keyName, redirect)
+			return
+		}
+
+		if admin != nil {
+			c.Set(AdminContextKey, admin)
+		} else {
+			c.Set(APIKeyContextKey, key)
+		}
+		c.Next()
+	}
+}
+
+// authenticate returns the admin or API key behind the request, trying the
+// session cookie and then the key from ExtractAPIKey. A nil admins or
+// apiKeys means that kind of principal is not accepted. On success exactly
+// one of admin and key is set; otherwise err is errKeyRequired,
+// errInvalidKey, service.ErrAPIKeyExpired or a lookup failure.
+func authenticate(c *gin.Context, admins *service.AdminService, apiKeys *service.APIKeyService, sessions *service.SessionService) (*models.Admin, *models.APIKey, error) {
+	sessionAdmin, sessionKey, err := sessionPrincipal(c, sessions)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sessionAdmin != nil && admins != nil {
+		return sessionAdmin, nil, nil
+	}
+	if sessionKey != nil && apiKeys != nil {
+		return nil, sessionKey, nil
+	}
+
+	rawKey := ExtractAPIKey(c)
+	if rawKey == "" {
+		return nil, nil, errKeyRequired
+	}
+
+	ctx := c.Request.Context()
+
+	// API keys are checked first: they make up most of the traffic, so the
+	// admin lookup only runs for keys that are not API keys
+	if apiKeys != nil {
+		key, err := apiKeys.ValidateKey(ctx, rawKey)
+		if err != nil || key != nil {
+			return nil, key, err
+		}
+	}
+
+	if admins != nil {
+		admin, err := admins.Authenticate(ctx, rawKey)
+		if err != nil || admin != nil {
+			return admin, nil, err
+		}
+	}
+
+	return nil, nil, errInvalidKey
+}
+
+// abortUnauthenticated answers a request that authenticate rejected with err.
+// Everything but lookup failures counts towards AuthFailureLimit.
+func abortUnauthenticated(c *gin.Context, err error, keyName string, redirect bool) {
+	var message string
+	switch {
+	case errors.Is(err, errKeyRequired):
+		message = strings.ToUpper(keyName[:1]) + keyName[1:] + " is required. " + apiKeyRequiredMessage
+		if redirect {
+			abortWithoutKey(c, message)
+			return
+		}
+	case errors.Is(err, errInvalidKey):
+		message = "Invalid or inactive " + keyName
+	case errors.Is(err, service.ErrAPIKeyExpired):
+		message = "API key has expired"
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Internal server error",
+		})
+		c.Abort()
+		return
+	}
+
+	MarkAuthFailed(c)
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": message,
+	})
+	c.Abort()
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/service"
+)
+
+func TestAbortUnauthenticated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		err         error
+		keyName     string
+		redirect    bool
+		accept      string
+		wantStatus  int
+		wantMessage string
+		wantFailed  bool
+	}{
+		{"missing admin key", errKeyRequired, "admin key", true, "", http.StatusForbidden, "Admin key is required. " + apiKeyRequiredMessage, true},
+		{"missing key from a browser", errKeyRequired, "API key", true, "text/html", http.StatusFound, "", true},
+		{"missing key without redirect", errKeyRequired, "API key", false, "text/html", http.StatusForbidden, "API key is required. " + apiKeyRequiredMessage, true},
+		{"invalid admin key", errInvalidKey, "admin key", true, "", http.StatusForbidden, "Invalid or inactive admin key", true},
+		{"invalid API key", errInvalidKey, "API key", false, "", http.StatusForbidden, "Invalid or inactive API key", true},
+		{"expired API key", service.ErrAPIKeyExpired, "API key", false, "", http.StatusForbidden, "API key has expired", true},
+		{"lookup failure", errors.New("connection refused"), "API key", false, "", http.StatusInternalServerError, "Internal server error", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/bookmarks", nil)
+			if tt.accept != "" {
+				c.Request.Header.Set("Accept", tt.accept)
+			}
+
+			abortUnauthenticated(c, tt.err, tt.keyName, tt.redirect)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if !c.IsAborted() {
+				t.Error("request was not aborted")
+			}
+			if failed := c.GetBool(authFailedContextKey); failed != tt.wantFailed {
+				t.Errorf("marked as failed authentication = %v, want %v", failed, tt.wantFailed)
+			}
+			if tt.wantMessage == "" {
+				return
+			}
+
+			var body struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error != tt.wantMessage {
+				t.Errorf("error = %q, want %q", body.Error, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestAuthenticateWithoutKey(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/bookmarks", nil)
+
+	// No header, cookie or query parameter: nothing is looked up
+	admin, key, err := authenticate(c, nil, nil, nil)
+	if admin != nil || key != nil || !errors.Is(err, errKeyRequired) {
+		t.Errorf("authenticate() = %v, %v, %v; want errKeyRequired", admin, key, err)
+	}
+}
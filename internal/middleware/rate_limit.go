@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/ratelimit"
+)
+
+// RateLimit limits requests per API key or admin, or per client IP for
+// requests without either (public routes). Keys can override the defaults
+// with their own limit. It must run after the auth middleware so the
+// key is known; requests rejected by the auth middleware never reach it and
+// are limited by AuthFailureLimit instead.
+//
+// Every limited response carries RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers; rejected requests get 429 with Retry-After. If the
+// limiter backend fails the request is let through.
+func RateLimit(limiter ratelimit.Limiter, defaults ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bucket := "ip:" + c.ClientIP()
+		limit := defaults
+
+		if key := CurrentAPIKey(c); key != nil {
+			bucket = "key:" + strconv.Itoa(key.ID)
+			limit = keyLimit(key, defaults)
+		} else if admin := CurrentAdmin(c); admin != nil {
+			bucket = "admin:" + strconv.Itoa(admin.ID)
+		}
+
+		if limit.PerMinute <= 0 {
+			c.Next()
+			return
+		}
+
+		res, err := limiter.Allow(c.Request.Context(), bucket, limit)
+		if err != nil {
+			log.Printf("ratelimit: failed to check %s: %v", bucket, err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+		if !res.Allowed {
+			abortRateLimited(c, res.RetryAfter)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authFailedContextKey is set on requests whose credentials were rejected,
+// for AuthFailureLimit.
+const authFailedContextKey = "auth_failed"
+
+// MarkAuthFailed records that the request's credentials were rejected, so it
+// counts against AuthFailureLimit. The auth middleware calls it; handlers
+// that check keys themselves, like the login form, do too.
+func MarkAuthFailed(c *gin.Context) {
+	c.Set(authFailedContextKey, true)
+}
+
+// AuthFailureLimit limits failed authentication per client IP. It must run
+// before the auth middleware: RateLimit only sees requests that got past
+// authentication, so without it keys could be guessed at any rate. Every
+// rejected request takes a token from the IP's bucket; once it is empty,
+// requests from the IP get 429 without their key being checked until a
+// token has refilled. Requests that authenticate cost nothing.
+//
+// Blocked IPs are remembered in memory, so checking costs no backend query;
+// with the Postgres backend each instance learns of a block on the next
+// failure it sees.
+func AuthFailureLimit(limiter ratelimit.Limiter, limit ratelimit.Limit) gin.HandlerFunc {
+	if limit.Burst <= 0 {
+		limit.Burst = max(limit.PerMinute, 1)
+	}
+	blocked := &blockedClients{until: make(map[string]time.Time)}
+
+	return func(c *gin.Context) {
+		if limit.PerMinute <= 0 {
+			c.Next()
+			return
+		}
+
+		ip := c.ClientIP()
+		if retryAfter := blocked.retryAfter(ip, time.Now()); retryAfter > 0 {
+			abortRateLimited(c, retryAfter)
+			return
+		}
+
+		c.Next()
+
+		if !c.GetBool(authFailedContextKey) {
+			return
+		}
+
+		bucket := "auth-failures:" + ip
+		res, err := limiter.Allow(c.Request.Context(), bucket, limit)
+		if err != nil {
+			log.Printf("ratelimit: failed to check %s: %v", bucket, err)
+			return
+		}
+		if !res.Allowed {
+			blocked.block(ip, time.Now().Add(res.RetryAfter))
+		}
+	}
+}
+
+// blockedClients remembers until when client IPs are blocked.
+type blockedClients struct {
+	mu        sync.Mutex
+	until     map[string]time.Time
+	lastSweep time.Time
+}
+
+func (b *blockedClients) retryAfter(ip string, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.until[ip].Sub(now)
+}
+
+func (b *blockedClients) block(ip string, until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(b.lastSweep) >= time.Minute {
+		b.lastSweep = now
+		for ip, blockedUntil := range b.until {
+			if blockedUntil.Before(now) {
+				delete(b.until, ip)
+			}
+		}
+	}
+
+	b.until[ip] = until
+}
+
+// abortRateLimited rejects the request with 429 and a Retry-After header.
+func abortRateLimited(c *gin.Context, retryAfter time.Duration) {
+	seconds := max(ceilSeconds(retryAfter), 1)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Rate limit exceeded",
+		"retry_after": seconds,
+	})
+	c.Abort()
+}
+
+// keyLimit applies a key's own limit over the defaults.
+func keyLimit(key *models.APIKey, defaults ratelimit.Limit) ratelimit.Limit {
+	limit := defaults
+	if key.RateLimitPerMinute != nil {
+		limit.PerMinute = *key.RateLimitPerMinute
+	}
+	if key.RateLimitBurst != nil {
+		limit.Burst = *key.RateLimitBurst
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = max(limit.PerMinute, 1)
+	}
+	return limit
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/ratelimit"
+)
+
+func TestAuthFailureLimitBlocksAfterFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	checked := 0
+	r := gin.New()
+	r.Use(AuthFailureLimit(ratelimit.NewMemoryLimiter(), ratelimit.Limit{PerMinute: 1, Burst: 3}))
+	r.GET("/", func(c *gin.Context) {
+		// Stands in for the auth middleware: only "good" authenticates
+		checked++
+		if c.Query("api") != "good" {
+			MarkAuthFailed(c)
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	get := func(key, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/?api="+key, nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Successful requests never use up the bucket
+	for range 10 {
+		if w := get("good", "192.0.2.1"); w.Code != http.StatusOK {
+			t.Fatalf("authenticated request got %d", w.Code)
+		}
+	}
+
+	// The burst, plus the failure that finds the bucket empty, reach auth
+	for i := range 4 {
+		if w := get("guess", "192.0.2.1"); w.Code != http.StatusForbidden {
+			t.Fatalf("failure %d got %d, want 403", i+1, w.Code)
+		}
+	}
+
+	checked = 0
+	for _, key := range []string{"guess", "good"} {
+		w := get(key, "192.0.2.1")
+		if w.Code != http.StatusTooManyRequests {
+			t.Errorf("blocked request with key %q got %d, want 429", key, w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" {
+			t.Errorf("blocked request has no Retry-After header")
+		}
+	}
+	if checked != 0 {
+		t.Errorf("blocked requests reached auth %d times", checked)
+	}
+
+	// Other clients are unaffected
+	if w := get("guess", "192.0.2.2"); w.Code != http.StatusForbidden {
+		t.Errorf("request from another IP got %d, want 403", w.Code)
+	}
+}
@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"context"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -9,7 +9,9 @@ import (
 	"fandom/notifications/internal/service"
 )
 
-func RequestLogger(logService *service.LogService) gin.HandlerFunc {
+// RequestLogger queues an entry for every request on logWriter, which
+// stores them in batches in the background.
+func RequestLogger(logWriter *service.LogWriter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
@@ -19,18 +21,22 @@ func RequestLogger(logService *service.LogService) gin.HandlerFunc {
 		// Calculate response time
 		responseTime := time.Since(start).Milliseconds()
 
-		// Extract API key from query params (if present)
-		apiKey := c.Query("api")
-		// Mask API key for logging (show only first 8 chars)
-		if len(apiKey) > 8 {
-			apiKey = apiKey[:8] + "..."
+		// Mask API key for logging (show only first 8 chars). Session
+		// logins carry no key, so use the prefix of their principal.
+		apiKey := maskAPIKey(ExtractAPIKey(c))
+		if apiKey == "" {
+			if key := CurrentAPIKey(c); key != nil {
+				apiKey = key.Prefix + "..."
+			} else if admin := CurrentAdmin(c); admin != nil {
+				apiKey = admin.Prefix + "..."
+			}
 		}
 
 		// Create log entry
-		logEntry := &models.RequestLog{
+		logEntry := models.RequestLog{
 			Method:       c.Request.Method,
 			Path:         c.Request.URL.Path,
-			QueryParams:  c.Request.URL.RawQuery,
+			QueryParams:  redactAPIKeyParam(c.Request.URL.RawQuery),
 			StatusCode:   c.Writer.Status(),
 			IPAddress:    c.ClientIP(),
 			UserAgent:    c.Request.UserAgent(),
@@ -39,12 +45,30 @@ func RequestLogger(logService *service.LogService) gin.HandlerFunc {
 			CreatedAt:    time.Now(),
 		}
 
-		// Log asynchronously to avoid blocking the response
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			_ = logService.LogRequest(ctx, logEntry)
-		}()
+		// Never blocks; the entry is dropped if the buffer is full
+		logWriter.Write(logEntry)
 	}
 }
 
+func maskAPIKey(apiKey string) string {
+	if len(apiKey) > 8 {
+		return apiKey[:8] + "..."
+	}
+	return apiKey
+}
+
+// redactAPIKeyParam masks the api query parameter so keys sent in the URL
+// are not stored in full in request_logs.
+func redactAPIKeyParam(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		if name, value, _ := strings.Cut(param, "="); name == "api" {
+			params[i] = "api=" + maskAPIKey(value)
+		}
+	}
+	return strings.Join(params, "&")
+}
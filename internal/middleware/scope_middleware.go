@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/service"
+)
+
+// AdminOrAPIKeyAuth accepts either an admin key or a valid API key. Use it
+// with RequireRoleOrScope or RequireRole on routes that scoped keys may reach.
+func AdminOrAPIKeyAuth(adminService *service.AdminService, apiKeyService *service.APIKeyService, sessionService *service.SessionService) gin.HandlerFunc {
+	return keyAuth(adminService, apiKeyService, sessionService, "API key", true)
+}
+
+// RequireScope rejects requests whose API key lacks scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := CurrentAPIKey(c)
+		if key == nil || !key.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "API key is missing the '" + scope + "' scope",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRoleOrScope lets through admins with role (or a more privileged
+// one) and API keys with scope.
+func RequireRoleOrScope(role, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if admin := CurrentAdmin(c); admin != nil {
+			if !admin.HasRole(role) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Requires the '" + role + "' admin role",
+				})
+				c.Abort()
+				return
+			}
+
+			c.Next()
+			return
+		}
+
+		key := CurrentAPIKey(c)
+		if key == nil || !key.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "API key is missing the '" + scope + "' scope",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Admin roles, from least to most privileged. Each role can do everything the
+// previous one can.
+const (
+	// AdminRoleViewer can read the dashboard, logs, webhook deliveries and the
+	// API key list.
+	AdminRoleViewer = "viewer"
+	// AdminRoleOperator can also retry webhook deliveries and publish events.
+	AdminRoleOperator = "operator"
+	// AdminRoleOwner can also manage API keys and admins.
+	AdminRoleOwner = "owner"
+)
+
+// AdminRoles lists every role, least privileged first.
+var AdminRoles = []string{AdminRoleViewer, AdminRoleOperator, AdminRoleOwner}
+
+// Admin is a staff account for the admin API and dashboard. Like API keys,
+// only a SHA-256 digest of its key is stored.
+type Admin struct {
+	ID         int        `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Prefix     string     `json:"prefix" db:"key_prefix"`
+	Role       string     `json:"role" db:"role"`
+	Active     bool       `json:"active" db:"active"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+}
+
+// HasRole reports whether the admin's role is role or a more privileged one.
+func (a *Admin) HasRole(role string) bool {
+	have := slices.Index(AdminRoles, a.Role)
+	return have >= 0 && have >= slices.Index(AdminRoles, role)
+}
+
+type CreateAdminRequest struct {
+	Name string `json:"name" binding:"required"`
+	Role string `json:"role" binding:"required,oneof=viewer operator owner"`
+}
+
+// UpdateAdminRequest changes an admin's role or active flag. Omitted fields
+// are left unchanged.
+type UpdateAdminRequest struct {
+	Role   *string `json:"role" binding:"omitempty,oneof=viewer operator owner"`
+	Active *bool   `json:"active"`
+}
+
+type CreateAdminResponse struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Key       string    `json:"key"`
+	Prefix    string    `json:"prefix"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,23 +1,121 @@
 package models
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
+// API key scopes. Each protected route requires one of them.
+const (
+	ScopeBookmarksRead        = "bookmarks:read"
+	ScopeBookmarksWrite       = "bookmarks:write"
+	ScopeNotificationsRead    = "notifications:read"
+	ScopeNotificationsWrite   = "notifications:write"
+	ScopeNotificationsPublish = "notifications:publish"
+	ScopeWebhooksRead         = "webhooks:read"
+	ScopeWebhooksWrite        = "webhooks:write"
+	ScopePreferencesRead      = "preferences:read"
+	ScopePreferencesWrite     = "preferences:write"
+	ScopeLogsRead             = "logs:read"
+)
+
+// APIKeyScopes lists every scope a key can be granted.
+var APIKeyScopes = []string{
+	ScopeBookmarksRead,
+	ScopeBookmarksWrite,
+	ScopeNotificationsRead,
+	ScopeNotificationsWrite,
+	ScopeNotificationsPublish,
+	ScopeWebhooksRead,
+	ScopeWebhooksWrite,
+	ScopePreferencesRead,
+	ScopePreferencesWrite,
+	ScopeLogsRead,
+}
+
+// DefaultAPIKeyScopes are granted when a key is created without explicit
+// scopes: everything a regular key could do before scopes existed.
+// Publishing events and reading logs must be granted explicitly.
+var DefaultAPIKeyScopes = []string{
+	ScopeBookmarksRead,
+	ScopeBookmarksWrite,
+	ScopeNotificationsRead,
+	ScopeNotificationsWrite,
+	ScopeWebhooksRead,
+	ScopeWebhooksWrite,
+	ScopePreferencesRead,
+	ScopePreferencesWrite,
+}
+
+// APIKey is a stored API key. Only a SHA-256 digest of the key is kept; the
+// plaintext is shown once, when the key is created. ReplacedByID points to the
+// successor issued when the key was rotated. RateLimitPerMinute and
+// RateLimitBurst override the server-wide rate limit when set.
 type APIKey struct {
-	ID         int       `json:"id" db:"id"`
-	Key        string    `json:"key" db:"key"`
-	Name       string    `json:"name,omitempty" db:"name"`
-	Active     bool      `json:"active" db:"active"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	ID           int        `json:"id" db:"id"`
+	KeyHash      string     `json:"-" db:"key_hash"`
+	Prefix       string     `json:"prefix" db:"key_prefix"`
+	Name         string     `json:"name,omitempty" db:"name"`
+	Scopes       []string   `json:"scopes" db:"scopes"`
+	Active       bool       `json:"active" db:"active"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	ReplacedByID *int       `json:"replaced_by_id,omitempty" db:"replaced_by_id"`
+
+	RateLimitPerMinute *int `json:"rate_limit_per_minute,omitempty" db:"rate_limit_per_minute"`
+	RateLimitBurst     *int `json:"rate_limit_burst,omitempty" db:"rate_limit_burst"`
+
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
 	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
 }
 
+// Expired reports whether the key is past its expiry time.
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
 type CreateAPIKeyRequest struct {
 	Name string `json:"name" binding:"required"`
+	// Scopes defaults to DefaultAPIKeyScopes when empty.
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is optional; keys without it never expire.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type RotateAPIKeyRequest struct {
+	// GracePeriodSeconds is how long the old key keeps working. Defaults to
+	// API_KEY_ROTATION_GRACE; 0 expires it immediately.
+	GracePeriodSeconds *int `json:"grace_period_seconds" binding:"omitempty,min=0"`
+}
+
+type UpdateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// UpdateAPIKeyRateLimitRequest sets a key's rate limit. Omitted or null
+// fields fall back to the server defaults.
+type UpdateAPIKeyRateLimitRequest struct {
+	RequestsPerMinute *int `json:"requests_per_minute" binding:"omitempty,min=1"`
+	Burst             *int `json:"burst" binding:"omitempty,min=1"`
 }
 
 type CreateAPIKeyResponse struct {
-	Key       string    `json:"key"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
+	Key       string     `json:"key"`
+	Prefix    string     `json:"prefix"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
+type RotateAPIKeyResponse struct {
+	CreateAPIKeyResponse
+	ID int `json:"id"`
+	// PreviousKeyID stays valid until PreviousKeyExpiresAt.
+	PreviousKeyID        int       `json:"previous_key_id"`
+	PreviousKeyExpiresAt time.Time `json:"previous_key_expires_at"`
+}
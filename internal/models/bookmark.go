@@ -15,3 +15,56 @@ type Bookmark struct {
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
 
+type CreateBookmarkRequest struct {
+	UserID        string `json:"user_id" binding:"required"`
+	PublicationID string `json:"publication_id" binding:"required"`
+	ChapterID     string `json:"chapter_id" binding:"required"`
+	Image         string `json:"image"`
+	Chapter       string `json:"chapter"`
+	Volume        string `json:"volume"`
+	Name          string `json:"name"`
+}
+
+type UpdateBookmarkRequest struct {
+	ChapterID *string `json:"chapter_id"`
+	Image     *string `json:"image"`
+	Chapter   *string `json:"chapter"`
+	Volume    *string `json:"volume"`
+	Name      *string `json:"name"`
+}
+
+type BookmarkQueryParams struct {
+	Limit         int    `form:"limit"`
+	Page          int    `form:"page"`
+	Offset        int    `form:"offset"`
+	UserID        string `form:"user_id"`
+	PublicationID string `form:"publication_id"`
+}
+
+type UpsertBookmarkRequest struct {
+	UserID        string `json:"user_id" binding:"required"`
+	PublicationID string `json:"publication_id" binding:"required"`
+	ChapterID     string `json:"chapter_id" binding:"required"`
+	Chapter       string `json:"chapter"`
+	Volume        string `json:"volume"`
+	Image         string `json:"image"`
+	Name          string `json:"name"`
+}
+
+// BookmarkMovement describes how an upsert changed a user's reading position.
+type BookmarkMovement string
+
+const (
+	BookmarkCreated   BookmarkMovement = "created"
+	BookmarkForward   BookmarkMovement = "forward"
+	BookmarkBackward  BookmarkMovement = "backward"
+	BookmarkUnchanged BookmarkMovement = "unchanged"
+	// BookmarkChanged is reported when the position moved but chapter/volume
+	// are not numeric, so the direction cannot be determined.
+	BookmarkChanged BookmarkMovement = "changed"
+)
+
+type UpsertBookmarkResponse struct {
+	Bookmark *Bookmark        `json:"bookmark"`
+	Movement BookmarkMovement `json:"movement" enums:"created,forward,backward,unchanged,changed"`
+}
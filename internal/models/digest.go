@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// DigestPreference controls a user's daily email digest. The digest is sent
+// once per local day after SendHour in Timezone.
+type DigestPreference struct {
+	UserID     string     `json:"user_id" db:"user_id"`
+	Email      string     `json:"email" db:"email"`
+	Enabled    bool       `json:"enabled" db:"enabled"`
+	SendHour   int        `json:"send_hour" db:"send_hour"`
+	Timezone   string     `json:"timezone" db:"timezone"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty" db:"last_sent_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type SetDigestPreferenceRequest struct {
+	UserID   string `json:"user_id" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Enabled  *bool  `json:"enabled"`
+	SendHour *int   `json:"send_hour" binding:"omitempty,min=0,max=23"`
+	Timezone string `json:"timezone" example:"Europe/Berlin"`
+}
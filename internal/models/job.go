@@ -0,0 +1,25 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	JobStatusPending = "pending"
+	JobStatusDead    = "dead"
+)
+
+// Job is a unit of background work in the jobs table. Completed jobs are
+// deleted; jobs that exhaust their attempts stay behind in the dead state.
+type Job struct {
+	ID          int64           `json:"id" db:"id"`
+	Queue       string          `json:"queue" db:"queue"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	Status      string          `json:"status" db:"status"`
+	Attempts    int             `json:"attempts" db:"attempts"`
+	MaxAttempts int             `json:"max_attempts" db:"max_attempts"`
+	RunAt       time.Time       `json:"run_at" db:"run_at"`
+	LastError   string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
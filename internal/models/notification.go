@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+const NotificationTypeChapterReleased = "chapter_released"
+
+type Notification struct {
+	ID            int64      `json:"id" db:"id"`
+	UserID        string     `json:"user_id" db:"user_id"`
+	Type          string     `json:"type" db:"type"`
+	PublicationID string     `json:"publication_id" db:"publication_id"`
+	ChapterID     string     `json:"chapter_id" db:"chapter_id"`
+	Chapter       string     `json:"chapter,omitempty" db:"chapter"`
+	Volume        string     `json:"volume,omitempty" db:"volume"`
+	Name          string     `json:"name,omitempty" db:"name"`
+	Image         string     `json:"image,omitempty" db:"image"`
+	ReadAt        *time.Time `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type ChapterReleasedEvent struct {
+	PublicationID string `json:"publication_id" binding:"required"`
+	ChapterID     string `json:"chapter_id" binding:"required"`
+	Chapter       string `json:"chapter"`
+	Volume        string `json:"volume"`
+	Name          string `json:"name"`
+	Image         string `json:"image"`
+}
+
+type ChapterReleasedResponse struct {
+	PublicationID string `json:"publication_id"`
+	ChapterID     string `json:"chapter_id"`
+	Notified      int    `json:"notified"`
+}
+
+type NotificationQueryParams struct {
+	UserID     string `form:"user_id" binding:"required"`
+	Limit      int    `form:"limit"`
+	Cursor     string `form:"cursor"`
+	UnreadOnly bool   `form:"unread_only"`
+}
+
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	NextCursor    string         `json:"next_cursor,omitempty"`
+}
+
+type MarkNotificationsReadRequest struct {
+	UserID string  `json:"user_id" binding:"required"`
+	IDs    []int64 `json:"ids" binding:"required,min=1"`
+}
+
+type MarkAllNotificationsReadRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+type MarkNotificationsReadResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+type UnreadCountResponse struct {
+	UserID string `json:"user_id"`
+	Unread int64  `json:"unread"`
+}
+
+// WebSocketClientMessage is sent by WebSocket clients. Type is "subscribe"
+// (with UserID and optional LastEventID), "ack" (with IDs) or "ack_all".
+type WebSocketClientMessage struct {
+	Type        string  `json:"type"`
+	UserID      string  `json:"user_id,omitempty"`
+	LastEventID int64   `json:"last_event_id,omitempty"`
+	IDs         []int64 `json:"ids,omitempty"`
+}
+
+// WebSocketServerMessage is sent to WebSocket clients. Type is "subscribed",
+// "notification", "ack" or "error".
+type WebSocketServerMessage struct {
+	Type         string        `json:"type"`
+	UserID       string        `json:"user_id,omitempty"`
+	Notification *Notification `json:"notification,omitempty"`
+	Updated      *int64        `json:"updated,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
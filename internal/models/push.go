@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+type PushSubscription struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     string     `json:"user_id" db:"user_id"`
+	Endpoint   string     `json:"endpoint" db:"endpoint"`
+	P256dh     string     `json:"-" db:"p256dh"`
+	Auth       string     `json:"-" db:"auth"`
+	UserAgent  string     `json:"user_agent,omitempty" db:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+}
+
+// PushSubscriptionJSON mirrors the browser's PushSubscription.toJSON().
+type PushSubscriptionJSON struct {
+	Endpoint       string `json:"endpoint" binding:"required"`
+	ExpirationTime *int64 `json:"expirationTime"`
+	Keys           struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
+}
+
+type RegisterPushSubscriptionRequest struct {
+	UserID       string               `json:"user_id" binding:"required"`
+	Subscription PushSubscriptionJSON `json:"subscription" binding:"required"`
+}
+
+type VAPIDPublicKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+// PushMessage is the JSON payload delivered to the browser's service worker.
+type PushMessage struct {
+	Title        string       `json:"title"`
+	Body         string       `json:"body"`
+	Image        string       `json:"image,omitempty"`
+	Notification Notification `json:"notification"`
+}
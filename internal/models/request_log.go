@@ -26,20 +26,84 @@ type LogQueryParams struct {
 	EndDate    time.Time `form:"end_date" time_format:"2006-01-02T15:04:05Z07:00"`
 }
 
+// Stats bucket sizes for LogStats.Series.
+const (
+	StatsBucketMinute = "minute"
+	StatsBucketHour   = "hour"
+	StatsBucketDay    = "day"
+)
+
 type LogStats struct {
 	TotalRequests       int64         `json:"total_requests"`
 	AverageResponseTime float64       `json:"average_response_time_ms"`
+	Latency             Latency       `json:"latency"`
 	StatusCodes         map[int]int64 `json:"status_codes"`
 	TopPaths            []PathCount   `json:"top_paths"`
 	TopMethods          []MethodCount `json:"top_methods"`
+	// Bucket is the size of each Series entry: minute, hour or day.
+	Bucket string        `json:"bucket"`
+	Series []StatsBucket `json:"series"`
+	// Writer describes this instance's log buffer, not the stored logs.
+	Writer *LogWriterStats `json:"writer,omitempty"`
+}
+
+// LogWriterStats counts request logs handled by one instance since it started.
+type LogWriterStats struct {
+	Buffered int    `json:"buffered"`
+	Written  uint64 `json:"written"`
+	// Dropped entries arrived while the buffer was full.
+	Dropped uint64 `json:"dropped"`
+	// Failed entries were in batches the database rejected.
+	Failed uint64 `json:"failed"`
+}
+
+// Latency holds response time percentiles in milliseconds, interpolated
+// with percentile_cont. They are 0 when there were no requests.
+type Latency struct {
+	P50 float64 `json:"p50_ms"`
+	P90 float64 `json:"p90_ms"`
+	P99 float64 `json:"p99_ms"`
 }
 
 type PathCount struct {
-	Path  string `json:"path"`
-	Count int64  `json:"count"`
+	Path    string  `json:"path"`
+	Count   int64   `json:"count"`
+	Latency Latency `json:"latency"`
+}
+
+// StatsBucket counts the requests made in [Start, Start + bucket). Buckets
+// without requests are included, so the series has no gaps.
+type StatsBucket struct {
+	Start        time.Time `json:"start"`
+	Requests     int64     `json:"requests"`
+	ClientErrors int64     `json:"client_errors"`
+	ServerErrors int64     `json:"server_errors"`
+	// ErrorRate is ServerErrors / Requests, or 0 without requests.
+	ErrorRate float64 `json:"error_rate"`
 }
 
 type MethodCount struct {
 	Method string `json:"method"`
 	Count  int64  `json:"count"`
 }
+
+// LogPartition is one partition of request_logs. From and To are nil for
+// the default partition, which holds rows outside every other one.
+type LogPartition struct {
+	Name          string     `json:"name"`
+	From          *time.Time `json:"from,omitempty"`
+	To            *time.Time `json:"to,omitempty"`
+	Default       bool       `json:"default"`
+	SizeBytes     int64      `json:"size_bytes"`
+	EstimatedRows int64      `json:"estimated_rows"`
+}
+
+// LogPartitionsResponse lists the request_logs partitions with the policy
+// that maintains them.
+type LogPartitionsResponse struct {
+	Interval string `json:"interval"`
+	// RetentionSeconds is 0 when logs are kept forever.
+	RetentionSeconds int64          `json:"retention_seconds"`
+	TotalSizeBytes   int64          `json:"total_size_bytes"`
+	Partitions       []LogPartition `json:"partitions"`
+}
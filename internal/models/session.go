@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Session is a dashboard login created by /auth/set. It belongs to exactly
+// one admin or API key; the browser only holds an opaque token, whose
+// SHA-256 digest is stored as TokenHash.
+type Session struct {
+	ID        int64     `json:"id" db:"id"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	AdminID   *int      `json:"admin_id,omitempty" db:"admin_id"`
+	APIKeyID  *int      `json:"api_key_id,omitempty" db:"api_key_id"`
+	UserAgent string    `json:"user_agent,omitempty" db:"user_agent"`
+	IPAddress string    `json:"ip_address,omitempty" db:"ip_address"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
+
+type CreateSessionRequest struct {
+	APIKey string `json:"api_key" binding:"required"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Webhook event types partners can subscribe to.
+const (
+	WebhookEventChapterReleased     = "chapter.released"
+	WebhookEventNotificationCreated = "notification.created"
+)
+
+var WebhookEventTypes = []string{
+	WebhookEventChapterReleased,
+	WebhookEventNotificationCreated,
+}
+
+// Webhook delivery states. A delivery is dead once it has failed MaxAttempts times.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+type WebhookSubscription struct {
+	ID         int       `json:"id" db:"id"`
+	APIKeyID   int       `json:"api_key_id" db:"api_key_id"`
+	URL        string    `json:"url" db:"url"`
+	Secret     string    `json:"secret,omitempty" db:"secret"`
+	EventTypes []string  `json:"event_types" db:"event_types"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+}
+
+type WebhookDelivery struct {
+	ID             int64      `json:"id" db:"id"`
+	SubscriptionID int        `json:"subscription_id" db:"subscription_id"`
+	EventType      string     `json:"event_type" db:"event_type"`
+	Payload        []byte     `json:"-" db:"payload"`
+	Status         string     `json:"status" db:"status"`
+	Attempts       int        `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError      string     `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+
+	// Set when a delivery is claimed for sending
+	URL    string `json:"url,omitempty" db:"url"`
+	Secret string `json:"-" db:"secret"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID         int64     `json:"id" db:"id"`
+	DeliveryID int64     `json:"delivery_id" db:"delivery_id"`
+	Attempt    int       `json:"attempt" db:"attempt"`
+	StatusCode int       `json:"status_code,omitempty" db:"status_code"`
+	Error      string    `json:"error,omitempty" db:"error"`
+	Duration   int64     `json:"duration_ms" db:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type WebhookDeliveryQueryParams struct {
+	Limit          int    `form:"limit"`
+	Page           int    `form:"page"`
+	Offset         int    `form:"offset"`
+	Status         string `form:"status"`
+	SubscriptionID int    `form:"subscription_id"`
+	EventType      string `form:"event_type"`
+}
+
+// WebhookPayload is the JSON body POSTed to subscribers.
+type WebhookPayload struct {
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
@@ -0,0 +1,88 @@
+// Package netguard keeps requests to URLs supplied by API clients, such as
+// webhook URLs and push endpoints, away from the server's internal network.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrAddressNotAllowed is returned when a connection to an internal address
+// is refused.
+var ErrAddressNotAllowed = errors.New("connection to a loopback, private, link-local or other internal address refused")
+
+// blockedPrefixes are internal ranges the net.IP helpers in IPAllowed don't
+// cover.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT, also used by cloud metadata services
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, which maps to any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2002::/16"),      // 6to4, which embeds an IPv4 address
+	netip.MustParsePrefix("100::/64"),       // discard-only
+}
+
+// IPAllowed reports whether requests may be sent to ip. Loopback, private,
+// link-local (which includes cloud metadata endpoints such as
+// 169.254.169.254), unspecified and multicast addresses are refused, as are
+// the ranges in blockedPrefixes, so API keys cannot use the server to reach
+// internal services.
+func IPAllowed(ip net.IP) bool {
+	if ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() {
+		return false
+	}
+
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	// IPv4-mapped IPv6 addresses are checked as the IPv4 address they carry
+	addr = addr.Unmap()
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewHTTPClient returns a client whose connections are checked against
+// IPAllowed after DNS resolution, so a host that later resolves to an
+// internal address (DNS rebinding), or redirects to one, still cannot be
+// reached. Proxies are not used: the check would only see the proxy's
+// address.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !IPAllowed(ip) {
+				return fmt.Errorf("%w: %s", ErrAddressNotAllowed, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
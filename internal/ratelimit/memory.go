@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that have filled up are dropped; a
+// missing bucket is treated as full.
+const sweepInterval = time.Minute
+
+// MemoryLimiter keeps buckets in process memory. Limits are per instance, so
+// use PostgresLimiter when running several.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	fullAt    map[string]time.Time
+	lastSweep time.Time
+	// now is time.Now, replaced in tests.
+	now func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{fullAt: make(map[string]time.Time), now: time.Now}
+}
+
+func (m *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	fullAt := m.fullAt[key]
+	if fullAt.Before(now) {
+		fullAt = now
+	}
+
+	next := fullAt.Add(limit.interval())
+	if next.Sub(now) > limit.capacity() {
+		return result(false, fullAt.Sub(now), limit), nil
+	}
+
+	m.fullAt[key] = next
+	return result(true, next.Sub(now), limit), nil
+}
+
+func (m *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, fullAt := range m.fullAt {
+		if fullAt.Before(now) {
+			delete(m.fullAt, key)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fandom/notifications/internal/database"
+)
+
+// PostgresLimiter keeps buckets in the rate_limit_buckets table so every
+// instance shares the same limits. Each request is one atomic upsert.
+type PostgresLimiter struct {
+	db *database.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresLimiter(db *database.DB) *PostgresLimiter {
+	return &PostgresLimiter{db: db}
+}
+
+// allowAttempts bounds how often Allow tries again when the bucket is swept
+// between taking a token and reading it.
+const allowAttempts = 2
+
+func (p *PostgresLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	p.maybeSweep()
+
+	for range allowAttempts {
+		r, ok, err := p.allow(ctx, key, limit)
+		if err != nil || ok {
+			return r, err
+		}
+	}
+	return Result{}, fmt.Errorf("rate limit bucket %q was deleted %d times while checking it", key, allowAttempts)
+}
+
+// allow takes a token from the bucket. ok is false if the bucket was swept
+// before a rejection could be reported, so the caller should try again.
+func (p *PostgresLimiter) allow(ctx context.Context, key string, limit Limit) (r Result, ok bool, err error) {
+	interval := limit.interval().Seconds()
+	capacity := limit.capacity().Seconds()
+
+	// The update only happens while the bucket has a token left; otherwise no
+	// row is returned and the request is rejected.
+	query := `
+		INSERT INTO rate_limit_buckets AS b (key, full_at)
+		VALUES ($1, CURRENT_TIMESTAMP + make_interval(secs => $2))
+		ON CONFLICT (key) DO UPDATE
+		SET full_at = GREATEST(b.full_at, CURRENT_TIMESTAMP) + make_interval(secs => $2)
+		WHERE GREATEST(b.full_at, CURRENT_TIMESTAMP) + make_interval(secs => $2) <= CURRENT_TIMESTAMP + make_interval(secs => $3)
+		RETURNING EXTRACT(EPOCH FROM full_at - CURRENT_TIMESTAMP)::float8
+	`
+
+	var untilFull float64
+	err = p.db.Pool.QueryRow(ctx, query, key, interval, capacity).Scan(&untilFull)
+	if err == nil {
+		return result(true, durationSeconds(untilFull), limit), true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Result{}, false, err
+	}
+
+	err = p.db.Pool.QueryRow(ctx,
+		`SELECT EXTRACT(EPOCH FROM full_at - CURRENT_TIMESTAMP)::float8 FROM rate_limit_buckets WHERE key = $1`,
+		key,
+	).Scan(&untilFull)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Swept as full in between; the next attempt gets a fresh bucket
+		return Result{}, false, nil
+	}
+	if err != nil {
+		return Result{}, false, err
+	}
+
+	return result(false, durationSeconds(untilFull), limit), true, nil
+}
+
+// maybeSweep deletes buckets that have filled up, at most once per
+// sweepInterval per instance.
+func (p *PostgresLimiter) maybeSweep() {
+	p.mu.Lock()
+	now := time.Now()
+	due := now.Sub(p.lastSweep) >= sweepInterval
+	if due {
+		p.lastSweep = now
+	}
+	p.mu.Unlock()
+
+	if !due {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if _, err := p.db.Pool.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE full_at < CURRENT_TIMESTAMP`); err != nil {
+			log.Printf("ratelimit: failed to delete idle buckets: %v", err)
+		}
+	}()
+}
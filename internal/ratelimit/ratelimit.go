@@ -0,0 +1,72 @@
+// Package ratelimit implements token-bucket rate limiting with an in-memory
+// backend for single instances and a Postgres backend shared by all instances.
+//
+// A bucket is stored as the time it will be full again rather than as a token
+// count: each request pushes that time one token's worth of refill into the
+// future, and a request is rejected when that would put it more than a full
+// bucket ahead of now. This is equivalent to counting tokens but needs a
+// single value per bucket and makes idle buckets easy to spot.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit describes a token bucket: it holds up to Burst requests and refills
+// at PerMinute requests per minute.
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+// interval is how long one token takes to refill.
+func (l Limit) interval() time.Duration {
+	return time.Minute / time.Duration(l.PerMinute)
+}
+
+// capacity is how long an empty bucket takes to fill.
+func (l Limit) capacity() time.Duration {
+	return l.interval() * time.Duration(l.Burst)
+}
+
+// Result is the outcome of one Allow call.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request would be allowed. It is
+	// zero when Allowed is true.
+	RetryAfter time.Duration
+}
+
+// Limiter takes one token from the bucket identified by key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// result describes a bucket that will be full again after untilFull.
+func result(allowed bool, untilFull time.Duration, limit Limit) Result {
+	untilFull = max(untilFull, 0)
+	interval := limit.interval()
+
+	r := Result{
+		Allowed: allowed,
+		Limit:   limit.Burst,
+		// Every full interval of headroom left is one more request
+		Remaining: int((limit.capacity() - untilFull) / interval),
+		Reset:     untilFull,
+	}
+	if !allowed {
+		r.RetryAfter = max(untilFull+interval-limit.capacity(), 0)
+	}
+	return r
+}
+
+// durationSeconds converts seconds reported by Postgres into a Duration.
+func durationSeconds(seconds float64) time.Duration {
+	return time.Duration(math.Round(seconds * float64(time.Second)))
+}
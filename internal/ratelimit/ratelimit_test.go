@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testLimit refills one token per second and holds three.
+var testLimit = Limit{PerMinute: 60, Burst: 3}
+
+func TestResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowed   bool
+		untilFull time.Duration
+		want      Result
+	}{
+		{"full bucket", true, 0, Result{Allowed: true, Limit: 3, Remaining: 3}},
+		{"first token taken", true, time.Second, Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second}},
+		{"last token taken", true, 3 * time.Second, Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second}},
+		{"partly refilled", true, 1500 * time.Millisecond, Result{Allowed: true, Limit: 3, Remaining: 1, Reset: 1500 * time.Millisecond}},
+		{"empty", false, 3 * time.Second, Result{Allowed: false, Limit: 3, Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second}},
+		{"token half refilled", false, 2500 * time.Millisecond, Result{Allowed: false, Limit: 3, Remaining: 0, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+		{"clock skew", true, -time.Second, Result{Allowed: true, Limit: 3, Remaining: 3}},
+	}
+
+	for _, tt := range tests {
+		if got := result(tt.allowed, tt.untilFull, testLimit); got != tt.want {
+			t.Errorf("%s: result(%v, %v) = %+v, want %+v", tt.name, tt.allowed, tt.untilFull, got, tt.want)
+		}
+	}
+}
+
+func newTestMemoryLimiter(now *time.Time) *MemoryLimiter {
+	m := NewMemoryLimiter()
+	m.now = func() time.Time { return *now }
+	return m
+}
+
+func TestMemoryLimiterBurstAndRefill(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := newTestMemoryLimiter(&now)
+	ctx := context.Background()
+
+	allow := func(key string) Result {
+		t.Helper()
+		r, err := m.Allow(ctx, key, testLimit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	// The burst is available at once
+	for i, remaining := range []int{2, 1, 0} {
+		r := allow("client")
+		if !r.Allowed || r.Remaining != remaining || r.Reset != time.Duration(i+1)*time.Second || r.RetryAfter != 0 {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i+1, r, remaining)
+		}
+	}
+
+	r := allow("client")
+	if r.Allowed || r.Remaining != 0 || r.Reset != 3*time.Second || r.RetryAfter != time.Second {
+		t.Fatalf("request over the burst = %+v, want rejected, retry after 1s", r)
+	}
+
+	// Rejected requests take no token
+	now = now.Add(500 * time.Millisecond)
+	if r := allow("client"); r.Allowed || r.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("request after 500ms = %+v, want rejected, retry after 500ms", r)
+	}
+
+	// Other keys have their own bucket
+	if r := allow("other"); !r.Allowed || r.Remaining != 2 {
+		t.Errorf("other key = %+v, want allowed with 2 remaining", r)
+	}
+
+	// One token refills per second
+	now = now.Add(500 * time.Millisecond)
+	if r := allow("client"); !r.Allowed || r.Remaining != 0 {
+		t.Fatalf("request after 1s = %+v, want allowed with 0 remaining", r)
+	}
+	if r := allow("client"); r.Allowed {
+		t.Fatalf("second request after 1s = %+v, want rejected", r)
+	}
+
+	// A bucket left alone fills up completely, and no further
+	now = now.Add(time.Minute)
+	if r := allow("client"); !r.Allowed || r.Remaining != 2 || r.Reset != time.Second {
+		t.Errorf("request after idling = %+v, want a full bucket", r)
+	}
+}
+
+func TestMemoryLimiterSweepsFullBuckets(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := newTestMemoryLimiter(&now)
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b"} {
+		if _, err := m.Allow(ctx, key, testLimit); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(m.fullAt) != 2 {
+		t.Fatalf("%d buckets, want 2", len(m.fullAt))
+	}
+
+	// "a" keeps being used; "b" fills up and is dropped at the next sweep
+	now = now.Add(sweepInterval - time.Second)
+	if _, err := m.Allow(ctx, "a", testLimit); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.fullAt) != 2 {
+		t.Fatalf("swept before sweepInterval: %v", m.fullAt)
+	}
+
+	now = now.Add(time.Second)
+	if _, err := m.Allow(ctx, "a", testLimit); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.fullAt["b"]; ok || len(m.fullAt) != 1 {
+		t.Errorf("buckets after sweep = %v, want only a", m.fullAt)
+	}
+}
@@ -0,0 +1,110 @@
+package realtime
+
+import (
+	"sync"
+
+	"fandom/notifications/internal/models"
+)
+
+// subscriberBuffer is how many notifications may queue for a subscriber before
+// it is considered too slow and disconnected.
+const subscriberBuffer = 64
+
+// Hub fans newly created notifications out to the real-time connections of
+// the users they belong to. It only reaches connections held by this process;
+// clients catch up on anything they missed from the notifications table.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*Subscription]struct{}
+	closed      bool
+}
+
+// Subscription receives the notifications published for one user. C is closed
+// when the subscription ends: on Close, when the hub shuts down, or when the
+// subscriber falls too far behind.
+type Subscription struct {
+	UserID string
+	C      <-chan models.Notification
+
+	ch  chan models.Notification
+	hub *Hub
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[string]map[*Subscription]struct{})}
+}
+
+func (h *Hub) Subscribe(userID string) *Subscription {
+	ch := make(chan models.Notification, subscriberBuffer)
+	sub := &Subscription{UserID: userID, C: ch, ch: ch, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(ch)
+		return sub
+	}
+
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[userID][sub] = struct{}{}
+
+	return sub
+}
+
+// Close unsubscribes and closes C. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.removeLocked(s)
+}
+
+// Publish delivers each notification to the current subscribers of its user
+// without blocking. Subscribers whose buffer is full are disconnected.
+func (h *Hub) Publish(notifications ...models.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, n := range notifications {
+		for sub := range h.subscribers[n.UserID] {
+			select {
+			case sub.ch <- n:
+			default:
+				h.removeLocked(sub)
+			}
+		}
+	}
+}
+
+// Close disconnects every subscriber and rejects new ones. It is meant to be
+// registered with http.Server.RegisterOnShutdown so long-lived streams end
+// instead of holding up a graceful shutdown.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			h.removeLocked(sub)
+		}
+	}
+}
+
+func (h *Hub) removeLocked(sub *Subscription) {
+	subs, ok := h.subscribers[sub.UserID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.UserID)
+	}
+	close(sub.ch)
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/models"
+)
+
+const adminColumns = `id, name, key_hash, key_prefix, role, active, created_at, last_used_at`
+
+// ErrLastOwner is returned when a change would leave no active owner.
+var ErrLastOwner = errors.New("at least one active owner is required")
+
+type AdminRepository struct {
+	db *database.DB
+}
+
+func NewAdminRepository(db *database.DB) *AdminRepository {
+	return &AdminRepository{db: db}
+}
+
+func (r *AdminRepository) Create(ctx context.Context, name, keyHash, prefix, role string) (*models.Admin, error) {
+	query := `
+		INSERT INTO admins (name, key_hash, key_prefix, role)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + adminColumns
+
+	return scanAdmin(r.db.Pool.QueryRow(ctx, query, name, keyHash, prefix, role))
+}
+
+// Bootstrap creates an owner with the given key if there are no admins yet.
+// It reports whether one was created.
+func (r *AdminRepository) Bootstrap(ctx context.Context, name, keyHash, prefix string) (bool, error) {
+	query := `
+		INSERT INTO admins (name, key_hash, key_prefix, role)
+		SELECT $1, $2, $3, $4
+		WHERE NOT EXISTS (SELECT 1 FROM admins)
+		ON CONFLICT (key_hash) DO NOTHING
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, name, keyHash, prefix, models.AdminRoleOwner)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *AdminRepository) FindByHash(ctx context.Context, keyHash string) (*models.Admin, error) {
+	query := `
+		SELECT ` + adminColumns + `
+		FROM admins
+		WHERE key_hash = $1 AND active = TRUE
+	`
+
+	admin, err := scanAdmin(r.db.Pool.QueryRow(ctx, query, keyHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+func (r *AdminRepository) FindByID(ctx context.Context, id int) (*models.Admin, error) {
+	admin, err := scanAdmin(r.db.Pool.QueryRow(ctx, `SELECT `+adminColumns+` FROM admins WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+// UpdateLastUsedBatch sets last_used_at for many admins in one statement.
+// Times older than the stored value are ignored.
+func (r *AdminRepository) UpdateLastUsedBatch(ctx context.Context, usedAt map[int]time.Time) error {
+	ids := make([]int32, 0, len(usedAt))
+	times := make([]time.Time, 0, len(usedAt))
+	for id, t := range usedAt {
+		ids = append(ids, int32(id))
+		times = append(times, t)
+	}
+
+	query := `
+		UPDATE admins
+		SET last_used_at = used.at
+		FROM unnest($1::int[], $2::timestamp[]) AS used(id, at)
+		WHERE admins.id = used.id
+			AND (admins.last_used_at IS NULL OR admins.last_used_at < used.at)
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, ids, times)
+	return err
+}
+
+func (r *AdminRepository) List(ctx context.Context) ([]models.Admin, error) {
+	rows, err := r.db.Pool.Query(ctx, `SELECT `+adminColumns+` FROM admins ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	admins := []models.Admin{}
+	for rows.Next() {
+		admin, err := scanAdmin(rows)
+		if err != nil {
+			return nil, err
+		}
+		admins = append(admins, *admin)
+	}
+
+	return admins, rows.Err()
+}
+
+// Update changes an admin's role and active flag; nil values are left
+// unchanged. It returns nil if the admin does not exist and ErrLastOwner if
+// the change would leave no active owner.
+func (r *AdminRepository) Update(ctx context.Context, id int, role *string, active *bool) (*models.Admin, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE admins
+		SET role = COALESCE($2, role), active = COALESCE($3, active)
+		WHERE id = $1
+		RETURNING ` + adminColumns
+
+	admin, err := scanAdmin(tx.QueryRow(ctx, query, id, role, active))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := ensureOwnerRemains(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return admin, nil
+}
+
+// Delete removes an admin. It returns ErrLastOwner instead of deleting the
+// last active owner.
+func (r *AdminRepository) Delete(ctx context.Context, id int) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM admins WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if err := ensureOwnerRemains(ctx, tx); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// ensureOwnerRemains fails with ErrLastOwner if the transaction has left no
+// active owner. Owner rows are locked so two concurrent demotions cannot
+// both pass.
+func ensureOwnerRemains(ctx context.Context, tx pgx.Tx) error {
+	rows, err := tx.Query(ctx, `SELECT id FROM admins WHERE role = $1 AND active = TRUE FOR UPDATE`, models.AdminRoleOwner)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	owners := 0
+	for rows.Next() {
+		owners++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if owners == 0 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func scanAdmin(row pgx.Row) (*models.Admin, error) {
+	var admin models.Admin
+	var prefix sql.NullString
+	var lastUsedAt sql.NullTime
+
+	err := row.Scan(
+		&admin.ID,
+		&admin.Name,
+		&admin.KeyHash,
+		&prefix,
+		&admin.Role,
+		&admin.Active,
+		&admin.CreatedAt,
+		&lastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	admin.Prefix = prefix.String
+	if lastUsedAt.Valid {
+		admin.LastUsedAt = &lastUsedAt.Time
+	}
+	return &admin, nil
+}
@@ -7,10 +7,16 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+
 	"fandom/notifications/internal/database"
 	"fandom/notifications/internal/models"
 )
 
+const apiKeyColumns = `id, key_hash, key_prefix, name, scopes, active, expires_at, replaced_by_id, rate_limit_per_minute, rate_limit_burst, created_at, last_used_at`
+
+// ErrAPIKeyInactive is returned when rotating a revoked key.
+var ErrAPIKeyInactive = errors.New("api key is inactive")
+
 type APIKeyRepository struct {
 	db *database.DB
 }
@@ -19,53 +25,23 @@ func NewAPIKeyRepository(db *database.DB) *APIKeyRepository {
 	return &APIKeyRepository{db: db}
 }
 
-func (r *APIKeyRepository) Create(ctx context.Context, key, name string) (*models.APIKey, error) {
+func (r *APIKeyRepository) Create(ctx context.Context, keyHash, prefix, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, error) {
 	query := `
-		INSERT INTO api_keys (key, name, active)
-		VALUES ($1, $2, TRUE)
-		RETURNING id, key, name, active, created_at, last_used_at
-	`
+		INSERT INTO api_keys (key_hash, key_prefix, name, scopes, expires_at, active)
+		VALUES ($1, $2, $3, $4, $5, TRUE)
+		RETURNING ` + apiKeyColumns
 
-	var apiKey models.APIKey
-	var lastUsedAt sql.NullTime
-
-	err := r.db.Pool.QueryRow(ctx, query, key, name).Scan(
-		&apiKey.ID,
-		&apiKey.Key,
-		&apiKey.Name,
-		&apiKey.Active,
-		&apiKey.CreatedAt,
-		&lastUsedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	if lastUsedAt.Valid {
-		apiKey.LastUsedAt = &lastUsedAt.Time
-	}
-
-	return &apiKey, nil
+	return scanAPIKey(r.db.Pool.QueryRow(ctx, query, keyHash, prefix, name, scopes, expiresAt))
 }
 
-func (r *APIKeyRepository) FindByKey(ctx context.Context, key string) (*models.APIKey, error) {
+func (r *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
 	query := `
-		SELECT id, key, name, active, created_at, last_used_at
+		SELECT ` + apiKeyColumns + `
 		FROM api_keys
-		WHERE key = $1 AND active = TRUE
+		WHERE key_hash = $1 AND active = TRUE
 	`
 
-	var apiKey models.APIKey
-	var lastUsedAt sql.NullTime
-
-	err := r.db.Pool.QueryRow(ctx, query, key).Scan(
-		&apiKey.ID,
-		&apiKey.Key,
-		&apiKey.Name,
-		&apiKey.Active,
-		&apiKey.CreatedAt,
-		&lastUsedAt,
-	)
+	apiKey, err := scanAPIKey(r.db.Pool.QueryRow(ctx, query, keyHash))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -73,27 +49,34 @@ func (r *APIKeyRepository) FindByKey(ctx context.Context, key string) (*models.A
 		return nil, err
 	}
 
-	if lastUsedAt.Valid {
-		apiKey.LastUsedAt = &lastUsedAt.Time
-	}
-
-	return &apiKey, nil
+	return apiKey, nil
 }
 
-func (r *APIKeyRepository) UpdateLastUsed(ctx context.Context, key string) error {
+// UpdateLastUsedBatch sets last_used_at for many keys in one statement. Times
+// older than the stored value are ignored.
+func (r *APIKeyRepository) UpdateLastUsedBatch(ctx context.Context, usedAt map[int]time.Time) error {
+	ids := make([]int32, 0, len(usedAt))
+	times := make([]time.Time, 0, len(usedAt))
+	for id, t := range usedAt {
+		ids = append(ids, int32(id))
+		times = append(times, t)
+	}
+
 	query := `
 		UPDATE api_keys
-		SET last_used_at = $1
-		WHERE key = $2
+		SET last_used_at = used.at
+		FROM unnest($1::int[], $2::timestamp[]) AS used(id, at)
+		WHERE api_keys.id = used.id
+			AND (api_keys.last_used_at IS NULL OR api_keys.last_used_at < used.at)
 	`
 
-	_, err := r.db.Pool.Exec(ctx, query, time.Now(), key)
+	_, err := r.db.Pool.Exec(ctx, query, ids, times)
 	return err
 }
 
 func (r *APIKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
 	query := `
-		SELECT id, key, name, active, created_at, last_used_at
+		SELECT ` + apiKeyColumns + `
 		FROM api_keys
 		ORDER BY created_at DESC
 	`
@@ -104,30 +87,201 @@ func (r *APIKeyRepository) List(ctx context.Context) ([]models.APIKey, error) {
 	}
 	defer rows.Close()
 
-	var apiKeys []models.APIKey
+	apiKeys := []models.APIKey{}
 	for rows.Next() {
-		var apiKey models.APIKey
-		var lastUsedAt sql.NullTime
-
-		err := rows.Scan(
-			&apiKey.ID,
-			&apiKey.Key,
-			&apiKey.Name,
-			&apiKey.Active,
-			&apiKey.CreatedAt,
-			&lastUsedAt,
-		)
+		apiKey, err := scanAPIKey(rows)
 		if err != nil {
 			return nil, err
 		}
+		apiKeys = append(apiKeys, *apiKey)
+	}
+
+	return apiKeys, rows.Err()
+}
+
+func (r *APIKeyRepository) FindByID(ctx context.Context, id int) (*models.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE id = $1
+	`
 
-		if lastUsedAt.Valid {
-			apiKey.LastUsedAt = &lastUsedAt.Time
+	apiKey, err := scanAPIKey(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
 		}
+		return nil, err
+	}
+
+	return apiKey, nil
+}
 
-		apiKeys = append(apiKeys, apiKey)
+// SetActive revokes or reactivates a key. It returns nil if the key does not exist.
+func (r *APIKeyRepository) SetActive(ctx context.Context, id int, active bool) (*models.APIKey, error) {
+	query := `
+		UPDATE api_keys
+		SET active = $2
+		WHERE id = $1
+		RETURNING ` + apiKeyColumns
+
+	apiKey, err := scanAPIKey(r.db.Pool.QueryRow(ctx, query, id, active))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
 	}
 
-	return apiKeys, rows.Err()
+	return apiKey, nil
 }
 
+// Rename changes a key's name. It returns nil if the key does not exist.
+func (r *APIKeyRepository) Rename(ctx context.Context, id int, name string) (*models.APIKey, error) {
+	query := `
+		UPDATE api_keys
+		SET name = $2
+		WHERE id = $1
+		RETURNING ` + apiKeyColumns
+
+	apiKey, err := scanAPIKey(r.db.Pool.QueryRow(ctx, query, id, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// SetRateLimit overrides a key's rate limit; nil values fall back to the
+// server defaults. It returns nil if the key does not exist.
+func (r *APIKeyRepository) SetRateLimit(ctx context.Context, id int, perMinute, burst *int) (*models.APIKey, error) {
+	query := `
+		UPDATE api_keys
+		SET rate_limit_per_minute = $2, rate_limit_burst = $3
+		WHERE id = $1
+		RETURNING ` + apiKeyColumns
+
+	apiKey, err := scanAPIKey(r.db.Pool.QueryRow(ctx, query, id, perMinute, burst))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// Rotate issues a successor for key id with the same name, scopes and rate
+// limit, makes the old key expire after grace (or keep an earlier expiry), and
+// moves its webhook subscriptions to the successor. It returns nil keys if id does not
+// exist and ErrAPIKeyInactive if it has been revoked.
+func (r *APIKeyRepository) Rotate(ctx context.Context, id int, keyHash, prefix string, grace time.Duration) (successor, previous *models.APIKey, err error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	previous, err = scanAPIKey(tx.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1 FOR UPDATE`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	if !previous.Active {
+		return nil, nil, ErrAPIKeyInactive
+	}
+
+	insertQuery := `
+		INSERT INTO api_keys (key_hash, key_prefix, name, scopes, rate_limit_per_minute, rate_limit_burst, active)
+		VALUES ($1, $2, $3, $4, $5, $6, TRUE)
+		RETURNING ` + apiKeyColumns
+
+	successor, err = scanAPIKey(tx.QueryRow(ctx, insertQuery, keyHash, prefix, previous.Name, previous.Scopes, previous.RateLimitPerMinute, previous.RateLimitBurst))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	expireQuery := `
+		UPDATE api_keys
+		SET expires_at = LEAST(COALESCE(expires_at, 'infinity'), CURRENT_TIMESTAMP + make_interval(secs => $2)),
+			replaced_by_id = $3
+		WHERE id = $1
+		RETURNING ` + apiKeyColumns
+
+	previous, err = scanAPIKey(tx.QueryRow(ctx, expireQuery, id, grace.Seconds(), successor.ID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE webhook_subscriptions SET api_key_id = $2 WHERE api_key_id = $1`, id, successor.ID); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return successor, previous, nil
+}
+
+func (r *APIKeyRepository) Delete(ctx context.Context, id int) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM api_keys WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	var prefix, name sql.NullString
+	var expiresAt, lastUsedAt sql.NullTime
+	var replacedByID, rateLimitPerMinute, rateLimitBurst sql.NullInt32
+
+	err := row.Scan(
+		&apiKey.ID,
+		&apiKey.KeyHash,
+		&prefix,
+		&name,
+		&apiKey.Scopes,
+		&apiKey.Active,
+		&expiresAt,
+		&replacedByID,
+		&rateLimitPerMinute,
+		&rateLimitBurst,
+		&apiKey.CreatedAt,
+		&lastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey.Prefix = prefix.String
+	apiKey.Name = name.String
+	if expiresAt.Valid {
+		apiKey.ExpiresAt = &expiresAt.Time
+	}
+	if replacedByID.Valid {
+		id := int(replacedByID.Int32)
+		apiKey.ReplacedByID = &id
+	}
+	if rateLimitPerMinute.Valid {
+		perMinute := int(rateLimitPerMinute.Int32)
+		apiKey.RateLimitPerMinute = &perMinute
+	}
+	if rateLimitBurst.Valid {
+		burst := int(rateLimitBurst.Int32)
+		apiKey.RateLimitBurst = &burst
+	}
+	if lastUsedAt.Valid {
+		apiKey.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &apiKey, nil
+}
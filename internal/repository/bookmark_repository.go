@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/models"
+)
+
+const bookmarkColumns = `id, user_id, publication_id, chapter_id, image, chapter, volume, name, created_at, updated_at`
+
+// ErrBookmarkExists is returned when a user already has a bookmark for the publication.
+var ErrBookmarkExists = errors.New("bookmark already exists for this user and publication")
+
+type BookmarkRepository struct {
+	db *database.DB
+}
+
+func NewBookmarkRepository(db *database.DB) *BookmarkRepository {
+	return &BookmarkRepository{db: db}
+}
+
+func (r *BookmarkRepository) Create(ctx context.Context, req models.CreateBookmarkRequest) (*models.Bookmark, error) {
+	query := `
+		INSERT INTO bookmarks (user_id, publication_id, chapter_id, image, chapter, volume, name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + bookmarkColumns
+
+	row := r.db.Pool.QueryRow(
+		ctx,
+		query,
+		req.UserID,
+		req.PublicationID,
+		req.ChapterID,
+		nullString(req.Image),
+		nullString(req.Chapter),
+		nullString(req.Volume),
+		nullString(req.Name),
+	)
+
+	bookmark, err := scanBookmark(row)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrBookmarkExists
+		}
+		return nil, err
+	}
+
+	return bookmark, nil
+}
+
+// upsertAttempts bounds how often Upsert starts over when the bookmark is
+// deleted between its insert and its update.
+const upsertAttempts = 3
+
+// Upsert sets the reading position for a user and publication, creating the
+// bookmark if needed. It returns the stored bookmark and, when a row already
+// existed, its state before the update.
+//
+// It inserts first rather than selecting first: a SELECT ... FOR UPDATE has
+// nothing to lock before the row exists, so two concurrent first writes would
+// both report a creation. ON CONFLICT DO NOTHING instead waits for a racing
+// insert to commit, after which the existing row is locked and updated.
+func (r *BookmarkRepository) Upsert(ctx context.Context, req models.UpsertBookmarkRequest) (*models.Bookmark, *models.Bookmark, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	insertQuery := `
+		INSERT INTO bookmarks (user_id, publication_id, chapter_id, chapter, volume, image, name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, publication_id) DO NOTHING
+		RETURNING ` + bookmarkColumns
+
+	selectQuery := `
+		SELECT ` + bookmarkColumns + `
+		FROM bookmarks
+		WHERE user_id = $1 AND publication_id = $2
+		FOR UPDATE
+	`
+
+	updateQuery := `
+		UPDATE bookmarks
+		SET chapter_id = $2,
+			chapter = $3,
+			volume = $4,
+			image = COALESCE($5, image),
+			name = COALESCE($6, name),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING ` + bookmarkColumns
+
+	for range upsertAttempts {
+		bookmark, err := scanBookmark(tx.QueryRow(
+			ctx,
+			insertQuery,
+			req.UserID,
+			req.PublicationID,
+			req.ChapterID,
+			nullString(req.Chapter),
+			nullString(req.Volume),
+			nullString(req.Image),
+			nullString(req.Name),
+		))
+		if err == nil {
+			if err := tx.Commit(ctx); err != nil {
+				return nil, nil, err
+			}
+			return bookmark, nil, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, err
+		}
+
+		// The bookmark exists; each statement sees rows committed before it
+		// started, so the row that blocked the insert is visible here.
+		previous, err := scanBookmark(tx.QueryRow(ctx, selectQuery, req.UserID, req.PublicationID))
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Deleted since the insert; start over
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		bookmark, err = scanBookmark(tx.QueryRow(
+			ctx,
+			updateQuery,
+			previous.ID,
+			req.ChapterID,
+			nullString(req.Chapter),
+			nullString(req.Volume),
+			nullString(req.Image),
+			nullString(req.Name),
+		))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return nil, nil, err
+		}
+		return bookmark, previous, nil
+	}
+
+	return nil, nil, fmt.Errorf("bookmark for user %s and publication %s kept changing during upsert", req.UserID, req.PublicationID)
+}
+
+func (r *BookmarkRepository) FindByID(ctx context.Context, id int) (*models.Bookmark, error) {
+	query := `
+		SELECT ` + bookmarkColumns + `
+		FROM bookmarks
+		WHERE id = $1
+	`
+
+	bookmark, err := scanBookmark(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return bookmark, nil
+}
+
+func (r *BookmarkRepository) List(ctx context.Context, params models.BookmarkQueryParams) ([]models.Bookmark, int64, error) {
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argPos := 1
+
+	if params.UserID != "" {
+		where += fmt.Sprintf(" AND user_id = $%d", argPos)
+		args = append(args, params.UserID)
+		argPos++
+	}
+
+	if params.PublicationID != "" {
+		where += fmt.Sprintf(" AND publication_id = $%d", argPos)
+		args = append(args, params.PublicationID)
+		argPos++
+	}
+
+	// Count total
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM bookmarks %s", where)
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	if params.Limit <= 0 {
+		params.Limit = 100
+	}
+	if params.Limit > 1000 {
+		params.Limit = 1000
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM bookmarks
+		%s
+		ORDER BY updated_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, bookmarkColumns, where, argPos, argPos+1)
+
+	args = append(args, params.Limit, params.Offset)
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	bookmarks := []models.Bookmark{}
+	for rows.Next() {
+		bookmark, err := scanBookmark(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		bookmarks = append(bookmarks, *bookmark)
+	}
+
+	return bookmarks, total, rows.Err()
+}
+
+func (r *BookmarkRepository) Update(ctx context.Context, id int, req models.UpdateBookmarkRequest) (*models.Bookmark, error) {
+	// NULL parameters keep the current column value
+	query := `
+		UPDATE bookmarks
+		SET chapter_id = COALESCE($2, chapter_id),
+			image = COALESCE($3, image),
+			chapter = COALESCE($4, chapter),
+			volume = COALESCE($5, volume),
+			name = COALESCE($6, name),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING ` + bookmarkColumns
+
+	row := r.db.Pool.QueryRow(ctx, query, id, req.ChapterID, req.Image, req.Chapter, req.Volume, req.Name)
+
+	bookmark, err := scanBookmark(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return bookmark, nil
+}
+
+func (r *BookmarkRepository) Delete(ctx context.Context, id int) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM bookmarks WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanBookmark(row pgx.Row) (*models.Bookmark, error) {
+	var bookmark models.Bookmark
+	var image, chapter, volume, name sql.NullString
+
+	err := row.Scan(
+		&bookmark.ID,
+		&bookmark.UserID,
+		&bookmark.PublicationID,
+		&bookmark.ChapterID,
+		&image,
+		&chapter,
+		&volume,
+		&name,
+		&bookmark.CreatedAt,
+		&bookmark.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmark.Image = image.String
+	bookmark.Chapter = chapter.String
+	bookmark.Volume = volume.String
+	bookmark.Name = name.String
+
+	return &bookmark, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/models"
+)
+
+const digestPreferenceColumns = `user_id, email, enabled, send_hour, timezone, last_sent_at, created_at, updated_at`
+
+type DigestRepository struct {
+	db *database.DB
+}
+
+func NewDigestRepository(db *database.DB) *DigestRepository {
+	return &DigestRepository{db: db}
+}
+
+func (r *DigestRepository) Upsert(ctx context.Context, pref models.DigestPreference) (*models.DigestPreference, error) {
+	query := `
+		INSERT INTO digest_preferences (user_id, email, enabled, send_hour, timezone)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET email = EXCLUDED.email,
+			enabled = EXCLUDED.enabled,
+			send_hour = EXCLUDED.send_hour,
+			timezone = EXCLUDED.timezone,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING ` + digestPreferenceColumns
+
+	row := r.db.Pool.QueryRow(ctx, query, pref.UserID, pref.Email, pref.Enabled, pref.SendHour, pref.Timezone)
+	return scanDigestPreference(row)
+}
+
+func (r *DigestRepository) FindByUserID(ctx context.Context, userID string) (*models.DigestPreference, error) {
+	query := `
+		SELECT ` + digestPreferenceColumns + `
+		FROM digest_preferences
+		WHERE user_id = $1
+	`
+
+	pref, err := scanDigestPreference(r.db.Pool.QueryRow(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return pref, nil
+}
+
+func (r *DigestRepository) Delete(ctx context.Context, userID string) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM digest_preferences WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ClaimDue marks up to limit enabled preferences whose digest is due as sent
+// and returns them with LastSentAt holding the previous send time. A digest is
+// due once the user's local hour reaches send_hour and none was sent earlier
+// on the same local day. Concurrent schedulers skip rows claimed by others.
+func (r *DigestRepository) ClaimDue(ctx context.Context, limit int) ([]models.DigestPreference, error) {
+	query := `
+		UPDATE digest_preferences p
+		SET last_sent_at = CURRENT_TIMESTAMP
+		FROM (
+			SELECT user_id, last_sent_at AS previous_sent_at
+			FROM digest_preferences
+			WHERE enabled = TRUE
+				AND EXTRACT(HOUR FROM CURRENT_TIMESTAMP AT TIME ZONE timezone) >= send_hour
+				AND (
+					last_sent_at IS NULL
+					OR (last_sent_at AT TIME ZONE timezone)::date < (CURRENT_TIMESTAMP AT TIME ZONE timezone)::date
+				)
+			ORDER BY user_id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE p.user_id = due.user_id
+		RETURNING p.user_id, p.email, p.enabled, p.send_hour, p.timezone, due.previous_sent_at, p.created_at, p.updated_at
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prefs []models.DigestPreference
+	for rows.Next() {
+		pref, err := scanDigestPreference(rows)
+		if err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, *pref)
+	}
+
+	return prefs, rows.Err()
+}
+
+// RestoreLastSent undoes a claim after the digest could not be sent, so the
+// next scheduler run tries again.
+func (r *DigestRepository) RestoreLastSent(ctx context.Context, userID string, lastSentAt *time.Time) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE digest_preferences SET last_sent_at = $2 WHERE user_id = $1`, userID, lastSentAt)
+	return err
+}
+
+func scanDigestPreference(row pgx.Row) (*models.DigestPreference, error) {
+	var pref models.DigestPreference
+	var lastSentAt sql.NullTime
+
+	err := row.Scan(
+		&pref.UserID,
+		&pref.Email,
+		&pref.Enabled,
+		&pref.SendHour,
+		&pref.Timezone,
+		&lastSentAt,
+		&pref.CreatedAt,
+		&pref.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastSentAt.Valid {
+		pref.LastSentAt = &lastSentAt.Time
+	}
+
+	return &pref, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/models"
+)
+
+const jobColumns = `id, queue, payload, status, attempts, max_attempts, run_at, last_error, created_at`
+
+type JobRepository struct {
+	db *database.DB
+}
+
+func NewJobRepository(db *database.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// Enqueue inserts one job per payload, all due after delay, in a single
+// statement.
+func (r *JobRepository) Enqueue(ctx context.Context, queue string, payloads []string, delay time.Duration, maxAttempts int) error {
+	query := `
+		INSERT INTO jobs (queue, payload, run_at, max_attempts)
+		SELECT $1::VARCHAR, p.payload::jsonb, CURRENT_TIMESTAMP + make_interval(secs => $3), $4::INTEGER
+		FROM unnest($2::text[]) AS p(payload)
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, queue, payloads, delay.Seconds(), maxAttempts)
+	return err
+}
+
+// Claim locks up to limit due jobs from queue, counts the attempt and pushes
+// run_at out by visibility so other workers skip them meanwhile. A job whose
+// worker dies becomes due again once the visibility timeout passes.
+func (r *JobRepository) Claim(ctx context.Context, queue string, limit int, visibility time.Duration) ([]models.Job, error) {
+	query := `
+		UPDATE jobs
+		SET attempts = attempts + 1,
+			run_at = CURRENT_TIMESTAMP + make_interval(secs => $3)
+		WHERE id IN (
+			SELECT id
+			FROM jobs
+			WHERE queue = $1 AND status = 'pending' AND run_at <= CURRENT_TIMESTAMP
+			ORDER BY run_at, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+
+	rows, err := r.db.Pool.Query(ctx, query, queue, limit, visibility.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []models.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// Complete deletes a finished job. The attempt number guards against
+// removing a job another worker re-claimed after our visibility timeout.
+func (r *JobRepository) Complete(ctx context.Context, id int64, attempt int) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM jobs WHERE id = $1 AND attempts = $2`, id, attempt)
+	return err
+}
+
+// Fail records a failed attempt and either schedules a retry after retryIn
+// or, when dead, leaves the job in the dead state for inspection.
+func (r *JobRepository) Fail(ctx context.Context, id int64, attempt int, lastError string, retryIn time.Duration, dead bool) error {
+	status := models.JobStatusPending
+	if dead {
+		status = models.JobStatusDead
+	}
+
+	query := `
+		UPDATE jobs
+		SET status = $3,
+			last_error = $4,
+			run_at = CURRENT_TIMESTAMP + make_interval(secs => $5)
+		WHERE id = $1 AND attempts = $2
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, id, attempt, status, lastError, retryIn.Seconds())
+	return err
+}
+
+func scanJob(row pgx.Row) (*models.Job, error) {
+	var job models.Job
+	var payload []byte
+	var lastError sql.NullString
+
+	err := row.Scan(
+		&job.ID,
+		&job.Queue,
+		&payload,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.RunAt,
+		&lastError,
+		&job.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Payload = payload
+	job.LastError = lastError.String
+
+	return &job, nil
+}
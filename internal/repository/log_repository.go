@@ -3,10 +3,15 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"fandom/notifications/internal/database"
 	"fandom/notifications/internal/models"
 )
@@ -19,73 +24,302 @@ func NewLogRepository(db *database.DB) *LogRepository {
 	return &LogRepository{db: db}
 }
 
-func (r *LogRepository) Create(ctx context.Context, log *models.RequestLog) error {
+// requestLogColumns are the columns CreateBatch copies, in row order.
+var requestLogColumns = []string{"method", "path", "query_params", "status_code", "ip_address", "user_agent", "api_key", "response_time_ms", "created_at"}
+
+// CreateBatch inserts logs with COPY and returns how many rows were written.
+// IDs are not read back.
+//
+// created_at is a TIMESTAMP without time zone holding UTC, which is also how
+// partition bounds are computed. pgx writes the wall-clock time of a
+// time.Time as it is, so times are converted to UTC first.
+func (r *LogRepository) CreateBatch(ctx context.Context, logs []models.RequestLog) (int64, error) {
+	return r.db.Pool.CopyFrom(
+		ctx,
+		pgx.Identifier{"request_logs"},
+		requestLogColumns,
+		pgx.CopyFromSlice(len(logs), func(i int) ([]any, error) {
+			log := logs[i]
+			return []any{
+				log.Method,
+				log.Path,
+				log.QueryParams,
+				log.StatusCode,
+				log.IPAddress,
+				log.UserAgent,
+				log.APIKey,
+				log.ResponseTime,
+				log.CreatedAt.UTC(),
+			}, nil
+		}),
+	)
+}
+
+// logPartitionBound matches the bounds of a range partition as printed by
+// pg_get_expr.
+var logPartitionBound = regexp.MustCompile(`FROM \('([^']+)'\) TO \('([^']+)'\)`)
+
+// ListPartitions returns the partitions of request_logs ordered by their
+// lower bound, the default partition first. Bounds are UTC like created_at
+// itself.
+func (r *LogRepository) ListPartitions(ctx context.Context) ([]models.LogPartition, error) {
 	query := `
-		INSERT INTO request_logs (method, path, query_params, status_code, ip_address, user_agent, api_key, response_time_ms, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id
+		SELECT c.relname, pg_get_expr(c.relpartbound, c.oid), pg_total_relation_size(c.oid), GREATEST(c.reltuples, 0)::BIGINT
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'request_logs'::regclass
 	`
 
-	err := r.db.Pool.QueryRow(
-		ctx,
-		query,
-		log.Method,
-		log.Path,
-		log.QueryParams,
-		log.StatusCode,
-		log.IPAddress,
-		log.UserAgent,
-		log.APIKey,
-		log.ResponseTime,
-		log.CreatedAt,
-	).Scan(&log.ID)
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	return err
+	var partitions []models.LogPartition
+	for rows.Next() {
+		var partition models.LogPartition
+		var bound string
+		if err := rows.Scan(&partition.Name, &bound, &partition.SizeBytes, &partition.EstimatedRows); err != nil {
+			return nil, err
+		}
+
+		if match := logPartitionBound.FindStringSubmatch(bound); match != nil {
+			from, err := time.ParseInLocation("2006-01-02 15:04:05", match[1], time.UTC)
+			if err != nil {
+				return nil, fmt.Errorf("partition %s: %w", partition.Name, err)
+			}
+			to, err := time.ParseInLocation("2006-01-02 15:04:05", match[2], time.UTC)
+			if err != nil {
+				return nil, fmt.Errorf("partition %s: %w", partition.Name, err)
+			}
+			partition.From = &from
+			partition.To = &to
+		} else if bound == "DEFAULT" {
+			partition.Default = true
+		}
+
+		partitions = append(partitions, partition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].From == nil || partitions[j].From == nil {
+			return partitions[i].From == nil && partitions[j].From != nil
+		}
+		return partitions[i].From.Before(*partitions[j].From)
+	})
+	return partitions, nil
 }
 
-func (r *LogRepository) List(ctx context.Context, params models.LogQueryParams) ([]models.RequestLog, int64, error) {
-	where := []string{"1=1"}
-	args := []interface{}{}
-	argPos := 1
+// partitionLockTimeout bounds how long partition maintenance waits for a
+// lock. Inserts queue up behind a waiting ACCESS EXCLUSIVE lock, so it is
+// better to give up and retry at the next run.
+const partitionLockTimeout = "5s"
 
-	if params.Method != "" {
-		where = append(where, fmt.Sprintf("method = $%d", argPos))
-		args = append(args, params.Method)
-		argPos++
+// ErrPartitionBusy is returned when partition maintenance gave up waiting for
+// a lock held by other queries, such as a long export.
+var ErrPartitionBusy = errors.New("request_logs is busy")
+
+// PartitionPeriod is the range of created_at a partition holds.
+type PartitionPeriod struct {
+	From time.Time
+	To   time.Time
+}
+
+// PartitionPeriods returns the periods ("day" or "month") that need a
+// partition so that the current one and the ahead after it are covered.
+// They start at after, the upper bound of the last existing partition, when
+// that is later than the current period. Periods are UTC days or months,
+// computed from the database clock.
+func (r *LogRepository) PartitionPeriods(ctx context.Context, interval string, after *time.Time, ahead int) ([]PartitionPeriod, error) {
+	query := `
+		WITH RECURSIVE bounds AS (
+			SELECT
+				GREATEST(date_trunc($1, CURRENT_TIMESTAMP AT TIME ZONE 'UTC'), $2::timestamp) AS start_at,
+				date_trunc($1, CURRENT_TIMESTAMP AT TIME ZONE 'UTC') + ($3::int + 1) * ('1 ' || $1)::interval AS until_at
+		), period(from_at, to_at) AS (
+			SELECT start_at, date_trunc($1, start_at) + ('1 ' || $1)::interval
+			FROM bounds
+			UNION ALL
+			SELECT to_at, to_at + ('1 ' || $1)::interval
+			FROM period, bounds
+			WHERE to_at < until_at
+		)
+		SELECT from_at, to_at
+		FROM period, bounds
+		WHERE from_at < until_at
+		ORDER BY from_at
+	`
+
+	var afterUTC *time.Time
+	if after != nil {
+		t := after.UTC()
+		afterUTC = &t
 	}
 
-	if params.StatusCode > 0 {
-		where = append(where, fmt.Sprintf("status_code = $%d", argPos))
-		args = append(args, params.StatusCode)
-		argPos++
+	rows, err := r.db.Pool.Query(ctx, query, interval, afterUTC, ahead)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	if params.Path != "" {
-		where = append(where, fmt.Sprintf("path LIKE $%d", argPos))
-		args = append(args, "%"+params.Path+"%")
-		argPos++
+	var periods []PartitionPeriod
+	for rows.Next() {
+		var period PartitionPeriod
+		if err := rows.Scan(&period.From, &period.To); err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
 	}
+	return periods, rows.Err()
+}
 
-	if !params.StartDate.IsZero() {
-		where = append(where, fmt.Sprintf("created_at >= $%d", argPos))
-		args = append(args, params.StartDate)
-		argPos++
+// Now returns the database clock, the one partition periods are computed
+// from.
+func (r *LogRepository) Now(ctx context.Context) (time.Time, error) {
+	var now time.Time
+	if err := r.db.Pool.QueryRow(ctx, `SELECT CURRENT_TIMESTAMP AT TIME ZONE 'UTC'`).Scan(&now); err != nil {
+		return time.Time{}, err
 	}
+	return now, nil
+}
 
-	if !params.EndDate.IsZero() {
-		where = append(where, fmt.Sprintf("created_at <= $%d", argPos))
-		args = append(args, params.EndDate)
-		argPos++
+// CreatePartition creates the partition holding rows created in [from, to),
+// named after the day from falls on. It does nothing if that partition
+// already exists.
+//
+// Postgres refuses to create a partition while the default partition holds
+// rows in its range, which happens when no instance was running to create
+// it in time. Those rows are moved into the new partition in the same
+// transaction: the default partition is detached, the partition created,
+// the rows moved and the default partition attached again.
+func (r *LogRepository) CreatePartition(ctx context.Context, from, to time.Time) error {
+	from, to = from.UTC(), to.UTC()
+	name := pgx.Identifier{"request_logs_p" + from.Format("20060102")}.Sanitize()
+	bounds := fmt.Sprintf(
+		`FOR VALUES FROM ('%s') TO ('%s')`,
+		from.Format("2006-01-02 15:04:05"),
+		to.Format("2006-01-02 15:04:05"),
+	)
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Creating a partition locks request_logs exclusively anyway; taking the
+	// lock first keeps rows from reaching the default partition between the
+	// check and the create
+	statements := []string{
+		`SET LOCAL lock_timeout = '` + partitionLockTimeout + `'`,
+		`LOCK TABLE request_logs IN ACCESS EXCLUSIVE MODE`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(ctx, statement); err != nil {
+			return partitionLockError(err)
+		}
 	}
 
-	whereClause := ""
-	if len(where) > 0 {
-		whereClause = "WHERE " + where[0]
-		for i := 1; i < len(where); i++ {
-			whereClause += " AND " + where[i]
+	var stranded bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM request_logs_default WHERE created_at >= $1 AND created_at < $2
+		)
+	`, from, to).Scan(&stranded)
+	if err != nil {
+		return err
+	}
+
+	if !stranded {
+		if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS `+name+` PARTITION OF request_logs `+bounds); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
+	statements = []string{
+		`ALTER TABLE request_logs DETACH PARTITION request_logs_default`,
+		`CREATE TABLE IF NOT EXISTS ` + name + ` PARTITION OF request_logs ` + bounds,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(ctx, statement); err != nil {
+			return err
 		}
 	}
 
+	query := `
+		WITH moved AS (
+			DELETE FROM request_logs_default
+			WHERE created_at >= $1 AND created_at < $2
+			RETURNING *
+		)
+		INSERT INTO request_logs SELECT * FROM moved
+	`
+	if _, err := tx.Exec(ctx, query, from, to); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `ALTER TABLE request_logs ATTACH PARTITION request_logs_default DEFAULT`); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DropPartition drops a partition with all of its rows. Dropping needs an
+// exclusive lock on request_logs, so it gives up with ErrPartitionBusy after
+// partitionLockTimeout instead of holding up inserts behind a long export.
+func (r *LogRepository) DropPartition(ctx context.Context, name string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SET LOCAL lock_timeout = '`+partitionLockTimeout+`'`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DROP TABLE IF EXISTS `+pgx.Identifier{name}.Sanitize()); err != nil {
+		return partitionLockError(err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// partitionLockError returns ErrPartitionBusy for errors caused by
+// partitionLockTimeout running out.
+func partitionLockError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "55P03" {
+		return fmt.Errorf("%w: %w", ErrPartitionBusy, err)
+	}
+	return err
+}
+
+// DeleteDefaultBefore deletes rows older than cutoff from the default
+// partition, which retention can't drop.
+func (r *LogRepository) DeleteDefaultBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM request_logs_default WHERE created_at < $1`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+const requestLogColumnList = `id, method, path, query_params, status_code, ip_address, user_agent, api_key, response_time_ms, created_at`
+
+// logExportBatchSize is how many rows Export fetches from its cursor at a time.
+const logExportBatchSize = 1000
+
+func (r *LogRepository) List(ctx context.Context, params models.LogQueryParams) ([]models.RequestLog, int64, error) {
+	whereClause, args := logFilters(params)
+	argPos := len(args) + 1
+
 	// Count total
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM request_logs %s", whereClause)
 	var total int64
@@ -103,7 +337,7 @@ func (r *LogRepository) List(ctx context.Context, params models.LogQueryParams)
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, method, path, query_params, status_code, ip_address, user_agent, api_key, response_time_ms, created_at
+		SELECT `+requestLogColumnList+`
 		FROM request_logs
 		%s
 		ORDER BY created_at DESC
@@ -120,61 +354,166 @@ func (r *LogRepository) List(ctx context.Context, params models.LogQueryParams)
 
 	var logs []models.RequestLog
 	for rows.Next() {
-		var log models.RequestLog
-		var queryParams sql.NullString
-		var userAgent sql.NullString
-		var apiKey sql.NullString
-
-		err := rows.Scan(
-			&log.ID,
-			&log.Method,
-			&log.Path,
-			&queryParams,
-			&log.StatusCode,
-			&log.IPAddress,
-			&userAgent,
-			&apiKey,
-			&log.ResponseTime,
-			&log.CreatedAt,
-		)
+		log, err := scanRequestLog(rows)
 		if err != nil {
 			return nil, 0, err
 		}
+		logs = append(logs, *log)
+	}
 
-		if queryParams.Valid {
-			log.QueryParams = queryParams.String
-		}
-		if userAgent.Valid {
-			log.UserAgent = userAgent.String
+	return logs, total, rows.Err()
+}
+
+// Export calls fn with every log matching the filters in params, oldest
+// first; pagination is ignored. Rows are read through a server-side cursor
+// in batches, so memory use doesn't grow with the number of rows. If fn
+// returns an error, Export stops and returns it.
+func (r *LogRepository) Export(ctx context.Context, params models.LogQueryParams, fn func(*models.RequestLog) error) error {
+	whereClause, args := logFilters(params)
+
+	return pgx.BeginTxFunc(ctx, r.db.Pool, pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		declare := fmt.Sprintf(`
+			DECLARE request_log_export NO SCROLL CURSOR FOR
+			SELECT `+requestLogColumnList+`
+			FROM request_logs
+			%s
+			ORDER BY created_at, id
+		`, whereClause)
+
+		if _, err := tx.Exec(ctx, declare, args...); err != nil {
+			return err
 		}
-		if apiKey.Valid {
-			log.APIKey = apiKey.String
+
+		fetch := fmt.Sprintf(`FETCH FORWARD %d FROM request_log_export`, logExportBatchSize)
+		for {
+			rows, err := tx.Query(ctx, fetch)
+			if err != nil {
+				return err
+			}
+
+			count := 0
+			for rows.Next() {
+				log, err := scanRequestLog(rows)
+				if err != nil {
+					rows.Close()
+					return err
+				}
+				if err := fn(log); err != nil {
+					rows.Close()
+					return err
+				}
+				count++
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			if count < logExportBatchSize {
+				return nil
+			}
 		}
+	})
+}
+
+// logFilters builds the WHERE clause and its arguments for the filters in
+// params. Placeholders start at $1.
+func logFilters(params models.LogQueryParams) (string, []interface{}) {
+	where := []string{"1=1"}
+	args := []interface{}{}
+	argPos := 1
+
+	if params.Method != "" {
+		where = append(where, fmt.Sprintf("method = $%d", argPos))
+		args = append(args, params.Method)
+		argPos++
+	}
 
-		logs = append(logs, log)
+	if params.StatusCode > 0 {
+		where = append(where, fmt.Sprintf("status_code = $%d", argPos))
+		args = append(args, params.StatusCode)
+		argPos++
 	}
 
-	return logs, total, rows.Err()
+	if params.Path != "" {
+		where = append(where, fmt.Sprintf("path LIKE $%d", argPos))
+		args = append(args, "%"+params.Path+"%")
+		argPos++
+	}
+
+	if !params.StartDate.IsZero() {
+		where = append(where, fmt.Sprintf("created_at >= $%d", argPos))
+		args = append(args, params.StartDate.UTC())
+		argPos++
+	}
+
+	if !params.EndDate.IsZero() {
+		where = append(where, fmt.Sprintf("created_at <= $%d", argPos))
+		args = append(args, params.EndDate.UTC())
+	}
+
+	return "WHERE " + strings.Join(where, " AND "), args
+}
+
+func scanRequestLog(rows pgx.Rows) (*models.RequestLog, error) {
+	var log models.RequestLog
+	var queryParams sql.NullString
+	var userAgent sql.NullString
+	var apiKey sql.NullString
+	var ipAddress sql.NullString
+
+	err := rows.Scan(
+		&log.ID,
+		&log.Method,
+		&log.Path,
+		&queryParams,
+		&log.StatusCode,
+		&ipAddress,
+		&userAgent,
+		&apiKey,
+		&log.ResponseTime,
+		&log.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.QueryParams = queryParams.String
+	log.IPAddress = ipAddress.String
+	log.UserAgent = userAgent.String
+	log.APIKey = apiKey.String
+	return &log, nil
 }
 
-func (r *LogRepository) GetStats(ctx context.Context, startDate, endDate time.Time) (*models.LogStats, error) {
+// latencyPercentiles computes the percentiles in models.Latency.
+const latencyPercentiles = `percentile_cont(ARRAY[0.5, 0.9, 0.99]) WITHIN GROUP (ORDER BY response_time_ms)`
+
+// GetStats aggregates the logs created between startDate and endDate, with
+// a series of bucket-sized intervals (models.StatsBucketMinute, Hour or Day).
+func (r *LogRepository) GetStats(ctx context.Context, startDate, endDate time.Time, bucket string) (*models.LogStats, error) {
+	// created_at holds UTC, see CreateBatch
+	startDate, endDate = startDate.UTC(), endDate.UTC()
+
 	stats := &models.LogStats{
 		StatusCodes: make(map[int]int64),
+		Bucket:      bucket,
 	}
 
 	// Total requests
 	var total sql.NullInt64
 	var avgResponseTime sql.NullFloat64
+	var percentiles []float64
 
 	query := `
 		SELECT 
 			COUNT(*) as total,
-			COALESCE(AVG(response_time_ms), 0) as avg_response_time
+			COALESCE(AVG(response_time_ms), 0) as avg_response_time,
+			` + latencyPercentiles + ` as percentiles
 		FROM request_logs
 		WHERE created_at >= $1 AND created_at <= $2
 	`
 
-	err := r.db.Pool.QueryRow(ctx, query, startDate, endDate).Scan(&total, &avgResponseTime)
+	err := r.db.Pool.QueryRow(ctx, query, startDate, endDate).Scan(&total, &avgResponseTime, &percentiles)
 	if err != nil && err != pgx.ErrNoRows {
 		return nil, err
 	}
@@ -185,6 +524,7 @@ func (r *LogRepository) GetStats(ctx context.Context, startDate, endDate time.Ti
 	if avgResponseTime.Valid {
 		stats.AverageResponseTime = avgResponseTime.Float64
 	}
+	stats.Latency = latencyFrom(percentiles)
 
 	// Status code distribution
 	statusQuery := `
@@ -212,7 +552,7 @@ func (r *LogRepository) GetStats(ctx context.Context, startDate, endDate time.Ti
 
 	// Top paths
 	pathQuery := `
-		SELECT path, COUNT(*) as count
+		SELECT path, COUNT(*) as count, ` + latencyPercentiles + ` as percentiles
 		FROM request_logs
 		WHERE created_at >= $1 AND created_at <= $2
 		GROUP BY path
@@ -228,9 +568,11 @@ func (r *LogRepository) GetStats(ctx context.Context, startDate, endDate time.Ti
 
 	for rows.Next() {
 		var pc models.PathCount
-		if err := rows.Scan(&pc.Path, &pc.Count); err != nil {
+		var pathPercentiles []float64
+		if err := rows.Scan(&pc.Path, &pc.Count, &pathPercentiles); err != nil {
 			return nil, err
 		}
+		pc.Latency = latencyFrom(pathPercentiles)
 		stats.TopPaths = append(stats.TopPaths, pc)
 	}
 
@@ -257,6 +599,54 @@ func (r *LogRepository) GetStats(ctx context.Context, startDate, endDate time.Ti
 		stats.TopMethods = append(stats.TopMethods, mc)
 	}
 
+	// Requests per bucket; generate_series fills in the empty ones
+	seriesQuery := `
+		WITH counts AS (
+			SELECT
+				date_trunc($3, created_at) as bucket,
+				COUNT(*) as requests,
+				COUNT(*) FILTER (WHERE status_code BETWEEN 400 AND 499) as client_errors,
+				COUNT(*) FILTER (WHERE status_code >= 500) as server_errors
+			FROM request_logs
+			WHERE created_at >= $1 AND created_at <= $2
+			GROUP BY 1
+		)
+		SELECT s.bucket, COALESCE(c.requests, 0), COALESCE(c.client_errors, 0), COALESCE(c.server_errors, 0)
+		FROM generate_series(date_trunc($3, $1::timestamp), $2::timestamp, ('1 ' || $3)::interval) AS s(bucket)
+		LEFT JOIN counts c ON c.bucket = s.bucket
+		ORDER BY s.bucket
+	`
+
+	rows, err = r.db.Pool.Query(ctx, seriesQuery, startDate, endDate, bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats.Series = []models.StatsBucket{}
+	for rows.Next() {
+		var b models.StatsBucket
+		if err := rows.Scan(&b.Start, &b.Requests, &b.ClientErrors, &b.ServerErrors); err != nil {
+			return nil, err
+		}
+		if b.Requests > 0 {
+			b.ErrorRate = float64(b.ServerErrors) / float64(b.Requests)
+		}
+		stats.Series = append(stats.Series, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return stats, nil
 }
 
+// latencyFrom maps the result of latencyPercentiles, which is NULL without
+// rows, to a models.Latency.
+func latencyFrom(percentiles []float64) models.Latency {
+	if len(percentiles) != 3 {
+		return models.Latency{}
+	}
+	return models.Latency{P50: percentiles[0], P90: percentiles[1], P99: percentiles[2]}
+}
+
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/models"
+)
+
+const notificationColumns = `id, user_id, type, publication_id, chapter_id, chapter, volume, name, image, read_at, created_at`
+
+type NotificationRepository struct {
+	db *database.DB
+}
+
+func NewNotificationRepository(db *database.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// CreateForBookmarks inserts one chapter_released notification for every user
+// bookmarking the event's publication. Users already notified about the chapter
+// are skipped, so replaying an event is safe. Only newly created rows are returned.
+func (r *NotificationRepository) CreateForBookmarks(ctx context.Context, event models.ChapterReleasedEvent) ([]models.Notification, error) {
+	query := `
+		INSERT INTO notifications (user_id, type, publication_id, chapter_id, chapter, volume, name, image)
+		SELECT b.user_id, $1::VARCHAR, b.publication_id, $3::VARCHAR, $4::VARCHAR, $5::VARCHAR, COALESCE($6, b.name), COALESCE($7, b.image)
+		FROM bookmarks b
+		WHERE b.publication_id = $2
+		ON CONFLICT (user_id, type, publication_id, chapter_id) DO NOTHING
+		RETURNING ` + notificationColumns
+
+	rows, err := r.db.Pool.Query(
+		ctx,
+		query,
+		models.NotificationTypeChapterReleased,
+		event.PublicationID,
+		event.ChapterID,
+		nullString(event.Chapter),
+		nullString(event.Volume),
+		nullString(event.Name),
+		nullString(event.Image),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, *notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+// ListByUser returns a user's notifications newest first. Only rows with an id
+// below beforeID are returned when beforeID is positive.
+func (r *NotificationRepository) ListByUser(ctx context.Context, userID string, beforeID int64, limit int, unreadOnly bool) ([]models.Notification, error) {
+	where := "WHERE user_id = $1"
+	args := []interface{}{userID}
+	argPos := 2
+
+	if beforeID > 0 {
+		where += fmt.Sprintf(" AND id < $%d", argPos)
+		args = append(args, beforeID)
+		argPos++
+	}
+
+	if unreadOnly {
+		where += " AND read_at IS NULL"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM notifications
+		%s
+		ORDER BY id DESC
+		LIMIT $%d
+	`, notificationColumns, where, argPos)
+
+	args = append(args, limit)
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, *notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+// ListAfter returns a user's notifications with an id above afterID, oldest first.
+func (r *NotificationRepository) ListAfter(ctx context.Context, userID string, afterID int64, limit int) ([]models.Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_id = $1 AND id > $2
+		ORDER BY id ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, *notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+// ListUnreadSince returns up to limit of the user's unread notifications
+// created after since, newest first, along with how many there are in total.
+func (r *NotificationRepository) ListUnreadSince(ctx context.Context, userID string, since time.Time, limit int) ([]models.Notification, int64, error) {
+	countQuery := `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE user_id = $1 AND read_at IS NULL AND created_at > $2
+	`
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, countQuery, userID, since).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return []models.Notification{}, 0, nil
+	}
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_id = $1 AND read_at IS NULL AND created_at > $2
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, since, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, *notification)
+	}
+
+	return notifications, total, rows.Err()
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE user_id = $1 AND read_at IS NULL
+	`
+
+	var count int64
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID string, ids []int64) (int64, error) {
+	query := `
+		UPDATE notifications
+		SET read_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND id = ANY($2) AND read_at IS NULL
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, userID, ids)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	query := `
+		UPDATE notifications
+		SET read_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND read_at IS NULL
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *NotificationRepository) Delete(ctx context.Context, userID string, id int64) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM notifications WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanNotification(row pgx.Row) (*models.Notification, error) {
+	var notification models.Notification
+	var chapter, volume, name, image sql.NullString
+	var readAt sql.NullTime
+
+	err := row.Scan(
+		&notification.ID,
+		&notification.UserID,
+		&notification.Type,
+		&notification.PublicationID,
+		&notification.ChapterID,
+		&chapter,
+		&volume,
+		&name,
+		&image,
+		&readAt,
+		&notification.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	notification.Chapter = chapter.String
+	notification.Volume = volume.String
+	notification.Name = name.String
+	notification.Image = image.String
+	if readAt.Valid {
+		notification.ReadAt = &readAt.Time
+	}
+
+	return &notification, nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/models"
+)
+
+type PushRepository struct {
+	db *database.DB
+}
+
+func NewPushRepository(db *database.DB) *PushRepository {
+	return &PushRepository{db: db}
+}
+
+// Upsert stores a subscription. Endpoints are unique per browser, so
+// re-registering an endpoint replaces its keys and owner.
+func (r *PushRepository) Upsert(ctx context.Context, sub models.PushSubscription) (*models.PushSubscription, error) {
+	query := `
+		INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth, user_agent)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (endpoint) DO UPDATE
+		SET user_id = EXCLUDED.user_id,
+			p256dh = EXCLUDED.p256dh,
+			auth = EXCLUDED.auth,
+			user_agent = EXCLUDED.user_agent
+		RETURNING id, user_id, endpoint, p256dh, auth, user_agent, created_at, last_used_at
+	`
+
+	row := r.db.Pool.QueryRow(ctx, query, sub.UserID, sub.Endpoint, sub.P256dh, sub.Auth, nullString(sub.UserAgent))
+
+	var stored models.PushSubscription
+	var userAgent sql.NullString
+	var lastUsedAt sql.NullTime
+
+	err := row.Scan(
+		&stored.ID,
+		&stored.UserID,
+		&stored.Endpoint,
+		&stored.P256dh,
+		&stored.Auth,
+		&userAgent,
+		&stored.CreatedAt,
+		&lastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stored.UserAgent = userAgent.String
+	if lastUsedAt.Valid {
+		stored.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &stored, nil
+}
+
+func (r *PushRepository) ListByUser(ctx context.Context, userID string) ([]models.PushSubscription, error) {
+	query := `
+		SELECT id, user_id, endpoint, p256dh, auth, user_agent, created_at, last_used_at
+		FROM push_subscriptions
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []models.PushSubscription{}
+	for rows.Next() {
+		var sub models.PushSubscription
+		var userAgent sql.NullString
+		var lastUsedAt sql.NullTime
+
+		err := rows.Scan(
+			&sub.ID,
+			&sub.UserID,
+			&sub.Endpoint,
+			&sub.P256dh,
+			&sub.Auth,
+			&userAgent,
+			&sub.CreatedAt,
+			&lastUsedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		sub.UserAgent = userAgent.String
+		if lastUsedAt.Valid {
+			sub.LastUsedAt = &lastUsedAt.Time
+		}
+
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+func (r *PushRepository) FindByID(ctx context.Context, id int) (*models.PushSubscription, error) {
+	query := `
+		SELECT id, user_id, endpoint, p256dh, auth, user_agent, created_at, last_used_at
+		FROM push_subscriptions
+		WHERE id = $1
+	`
+
+	var sub models.PushSubscription
+	var userAgent sql.NullString
+	var lastUsedAt sql.NullTime
+
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+		&sub.ID,
+		&sub.UserID,
+		&sub.Endpoint,
+		&sub.P256dh,
+		&sub.Auth,
+		&userAgent,
+		&sub.CreatedAt,
+		&lastUsedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	sub.UserAgent = userAgent.String
+	if lastUsedAt.Valid {
+		sub.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &sub, nil
+}
+
+func (r *PushRepository) DeleteByEndpoint(ctx context.Context, endpoint string) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM push_subscriptions WHERE endpoint = $1`, endpoint)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *PushRepository) DeleteByID(ctx context.Context, id int) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM push_subscriptions WHERE id = $1`, id)
+	return err
+}
+
+func (r *PushRepository) UpdateLastUsed(ctx context.Context, id int) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE push_subscriptions SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1`, id)
+	return err
+}
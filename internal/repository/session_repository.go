@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/models"
+)
+
+const sessionColumns = `id, token_hash, admin_id, api_key_id, user_agent, ip_address, created_at, expires_at`
+
+type SessionRepository struct {
+	db *database.DB
+}
+
+func NewSessionRepository(db *database.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create stores a session for exactly one of adminID and apiKeyID that
+// expires after ttl.
+func (r *SessionRepository) Create(ctx context.Context, tokenHash string, adminID, apiKeyID *int, userAgent, ipAddress string, ttl time.Duration) (*models.Session, error) {
+	query := `
+		INSERT INTO sessions (token_hash, admin_id, api_key_id, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP + make_interval(secs => $6))
+		RETURNING ` + sessionColumns
+
+	return scanSession(r.db.Pool.QueryRow(ctx, query, tokenHash, adminID, apiKeyID, userAgent, ipAddress, ttl.Seconds()))
+}
+
+// FindActive returns the unexpired session with tokenHash, or nil.
+func (r *SessionRepository) FindActive(ctx context.Context, tokenHash string) (*models.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE token_hash = $1 AND expires_at > CURRENT_TIMESTAMP
+	`
+
+	session, err := scanSession(r.db.Pool.QueryRow(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (r *SessionRepository) Delete(ctx context.Context, tokenHash string) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM sessions WHERE token_hash = $1`, tokenHash)
+	return err
+}
+
+func (r *SessionRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM sessions WHERE expires_at <= CURRENT_TIMESTAMP`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanSession(row pgx.Row) (*models.Session, error) {
+	var session models.Session
+	var adminID, apiKeyID sql.NullInt32
+	var userAgent, ipAddress sql.NullString
+
+	err := row.Scan(
+		&session.ID,
+		&session.TokenHash,
+		&adminID,
+		&apiKeyID,
+		&userAgent,
+		&ipAddress,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if adminID.Valid {
+		id := int(adminID.Int32)
+		session.AdminID = &id
+	}
+	if apiKeyID.Valid {
+		id := int(apiKeyID.Int32)
+		session.APIKeyID = &id
+	}
+	session.UserAgent = userAgent.String
+	session.IPAddress = ipAddress.String
+	return &session, nil
+}
@@ -0,0 +1,392 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/models"
+)
+
+const webhookDeliveryColumns = `d.id, d.subscription_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_error, d.created_at, d.delivered_at`
+
+type WebhookRepository struct {
+	db *database.DB
+}
+
+func NewWebhookRepository(db *database.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, apiKeyID int, url, secret string, eventTypes []string) (*models.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (api_key_id, url, secret, event_types)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, api_key_id, url, secret, event_types, active, created_at
+	`
+
+	var sub models.WebhookSubscription
+	err := r.db.Pool.QueryRow(ctx, query, apiKeyID, url, secret, eventTypes).Scan(
+		&sub.ID,
+		&sub.APIKeyID,
+		&sub.URL,
+		&sub.Secret,
+		&sub.EventTypes,
+		&sub.Active,
+		&sub.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context, apiKeyID int) ([]models.WebhookSubscription, error) {
+	query := `
+		SELECT id, api_key_id, url, event_types, active, created_at
+		FROM webhook_subscriptions
+		WHERE api_key_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, apiKeyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		var sub models.WebhookSubscription
+		err := rows.Scan(
+			&sub.ID,
+			&sub.APIKeyID,
+			&sub.URL,
+			&sub.EventTypes,
+			&sub.Active,
+			&sub.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, apiKeyID, id int) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1 AND api_key_id = $2`, id, apiKeyID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Enqueue creates one pending delivery per payload for every active
+// subscription to eventType and returns how many were created.
+func (r *WebhookRepository) Enqueue(ctx context.Context, eventType string, payloads []string) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_type, payload)
+		SELECT s.id, $1::VARCHAR, p.payload::jsonb
+		FROM webhook_subscriptions s
+		CROSS JOIN unnest($2::text[]) AS p(payload)
+		WHERE s.active = TRUE AND $1 = ANY(s.event_types)
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, eventType, payloads)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// EnqueueOnce creates a pending delivery of payload for every active
+// subscription to eventType that has not had one with dedupeKey before, and
+// returns how many were created.
+func (r *WebhookRepository) EnqueueOnce(ctx context.Context, eventType, dedupeKey, payload string) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_type, payload, dedupe_key)
+		SELECT s.id, $1::VARCHAR, $3::jsonb, $2::VARCHAR
+		FROM webhook_subscriptions s
+		WHERE s.active = TRUE AND $1 = ANY(s.event_types)
+		ON CONFLICT (subscription_id, event_type, dedupe_key) WHERE dedupe_key IS NOT NULL DO NOTHING
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, eventType, dedupeKey, payload)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ClaimDue locks up to limit pending deliveries whose next attempt is due,
+// counts the attempt and hides them from other workers for lease. A worker
+// that dies mid-delivery therefore only delays the retry until the lease ends.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1,
+			next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id
+			AND d.id IN (
+				SELECT id
+				FROM webhook_deliveries
+				WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+		RETURNING ` + webhookDeliveryColumns + `, s.url, s.secret
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, attempt *models.WebhookDeliveryAttempt) error {
+	query := `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	statusCode := sql.NullInt32{Int32: int32(attempt.StatusCode), Valid: attempt.StatusCode != 0}
+
+	return r.db.Pool.QueryRow(
+		ctx,
+		query,
+		attempt.DeliveryID,
+		attempt.Attempt,
+		statusCode,
+		nullString(attempt.Error),
+		attempt.Duration,
+	).Scan(&attempt.ID, &attempt.CreatedAt)
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id int64) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', delivered_at = CURRENT_TIMESTAMP, last_error = NULL
+		WHERE id = $1
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, id)
+	return err
+}
+
+// MarkFailed records a failed attempt. The delivery is retried after retryIn,
+// or moved to the dead state when dead is true.
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id int64, lastError string, retryIn time.Duration, dead bool) error {
+	status := models.WebhookDeliveryPending
+	if dead {
+		status = models.WebhookDeliveryDead
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2,
+			last_error = $3,
+			next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $4)
+		WHERE id = $1
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, id, status, lastError, retryIn.Seconds())
+	return err
+}
+
+// Requeue moves a dead delivery back to pending with a fresh attempt budget.
+func (r *WebhookRepository) Requeue(ctx context.Context, id int64) (bool, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'dead'
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, params models.WebhookDeliveryQueryParams) ([]models.WebhookDelivery, int64, error) {
+	where := "WHERE 1=1"
+	args := []interface{}{}
+	argPos := 1
+
+	if params.Status != "" {
+		where += fmt.Sprintf(" AND d.status = $%d", argPos)
+		args = append(args, params.Status)
+		argPos++
+	}
+
+	if params.SubscriptionID > 0 {
+		where += fmt.Sprintf(" AND d.subscription_id = $%d", argPos)
+		args = append(args, params.SubscriptionID)
+		argPos++
+	}
+
+	if params.EventType != "" {
+		where += fmt.Sprintf(" AND d.event_type = $%d", argPos)
+		args = append(args, params.EventType)
+		argPos++
+	}
+
+	// Count total
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM webhook_deliveries d %s", where)
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	if params.Limit <= 0 {
+		params.Limit = 100
+	}
+	if params.Limit > 1000 {
+		params.Limit = 1000
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, s.url, s.secret
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		%s
+		ORDER BY d.id DESC
+		LIMIT $%d OFFSET $%d
+	`, webhookDeliveryColumns, where, argPos, argPos+1)
+
+	args = append(args, params.Limit, params.Offset)
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, total, rows.Err()
+}
+
+func (r *WebhookRepository) ListAttempts(ctx context.Context, deliveryID int64) ([]models.WebhookDeliveryAttempt, error) {
+	query := `
+		SELECT id, delivery_id, attempt, status_code, error, duration_ms, created_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempt ASC, id ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []models.WebhookDeliveryAttempt{}
+	for rows.Next() {
+		var attempt models.WebhookDeliveryAttempt
+		var statusCode sql.NullInt32
+		var errMsg sql.NullString
+
+		err := rows.Scan(
+			&attempt.ID,
+			&attempt.DeliveryID,
+			&attempt.Attempt,
+			&statusCode,
+			&errMsg,
+			&attempt.Duration,
+			&attempt.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		attempt.StatusCode = int(statusCode.Int32)
+		attempt.Error = errMsg.String
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+func (r *WebhookRepository) FindDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `, s.url, s.secret
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE d.id = $1
+	`
+
+	delivery, err := scanWebhookDelivery(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// scanWebhookDelivery scans webhookDeliveryColumns followed by the
+// subscription's url and secret.
+func scanWebhookDelivery(row pgx.Row) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var lastError sql.NullString
+	var deliveredAt sql.NullTime
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&lastError,
+		&delivery.CreatedAt,
+		&deliveredAt,
+		&delivery.URL,
+		&delivery.Secret,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.LastError = lastError.String
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &delivery, nil
+}
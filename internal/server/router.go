@@ -1,6 +1,8 @@
 package server
 
 import (
+	"log"
+
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -8,12 +10,14 @@ import (
 	"fandom/notifications/internal/config"
 	"fandom/notifications/internal/database"
 	"fandom/notifications/internal/middleware"
+	"fandom/notifications/internal/ratelimit"
+	"fandom/notifications/internal/realtime"
 	"fandom/notifications/internal/repository"
 	"fandom/notifications/internal/server/transport"
 	"fandom/notifications/internal/service"
 )
 
-func NewRouter(cfg config.Config, db *database.DB) *gin.Engine {
+func NewRouter(cfg config.Config, db *database.DB, hub *realtime.Hub, adminService *service.AdminService, apiKeyService *service.APIKeyService, logWriter *service.LogWriter, logPartitions *service.LogPartitionManager, pushService *service.PushService) *gin.Engine {
 	gin.SetMode(cfg.GinMode)
 
 	r := gin.New()
@@ -22,34 +26,72 @@ func NewRouter(cfg config.Config, db *database.DB) *gin.Engine {
 	// Swagger UI (public)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Rate limiting: per API key behind the auth middleware, per client IP otherwise.
+	// Failed authentication is limited per client IP ahead of the auth middleware.
+	limiter := newRateLimiter(cfg, db)
+	rateLimit := middleware.RateLimit(limiter, ratelimit.Limit{
+		PerMinute: cfg.RateLimitPerMinute,
+		Burst:     cfg.RateLimitBurst,
+	})
+	authFailureLimit := middleware.AuthFailureLimit(limiter, ratelimit.Limit{
+		PerMinute: cfg.AuthFailuresPerMinute,
+		Burst:     cfg.AuthFailuresBurst,
+	})
+
+	// Dashboard logins; sessions resolve back to the admin or API key they were started with.
+	// Every group below checks CSRF tokens on session-authenticated writes.
+	sessionService := service.NewSessionService(repository.NewSessionRepository(db), adminService, apiKeyService, cfg.SessionTTL)
+
 	// Auth routes (public)
-	r.GET("/auth", transport.AuthPage)
-	r.POST("/auth/set", transport.SetAuthCookie)
-	r.POST("/auth/clear", transport.ClearAuthCookie)
+	auth := r.Group("/auth")
+	auth.Use(authFailureLimit, rateLimit)
+	transport.RegisterAuthRoutes(auth, sessionService, cfg.SessionCookieSecure)
 
 	// Initialize services
-	apiKeyRepo := repository.NewAPIKeyRepository(db)
-	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
 	logRepo := repository.NewLogRepository(db)
-	logService := service.NewLogService(logRepo)
+	logService := service.NewLogService(logRepo, logWriter, logPartitions)
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	bookmarkService := service.NewBookmarkService(bookmarkRepo)
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookService := service.NewWebhookService(webhookRepo)
+	digestRepo := repository.NewDigestRepository(db)
+	digestService := service.NewDigestService(digestRepo)
+	notificationRepo := repository.NewNotificationRepository(db)
+	notificationService := service.NewNotificationService(notificationRepo, hub, webhookService, pushService)
 
-	// Request logging middleware (applies to all routes except swagger)
-	r.Use(middleware.RequestLogger(logService))
+	// Request logging middleware (applies to all routes except swagger and /auth)
+	r.Use(middleware.RequestLogger(logWriter))
 
-	// Admin routes (require master API key)
+	// Admin routes (require an admin key)
 	admin := r.Group("/")
-	admin.Use(middleware.MasterKeyAuth(cfg.MasterAPIKey))
-	transport.RegisterAdminRoutes(admin, db, apiKeyService)
+	admin.Use(authFailureLimit, middleware.AdminAuth(adminService, sessionService), middleware.CSRFProtection(), rateLimit)
+	transport.RegisterAdminRoutes(admin, db, adminService, apiKeyService, cfg.APIKeyRotationGrace)
 
-	// Dashboard routes (require master API key)
+	// Dashboard routes (require an admin key; log APIs also accept logs:read keys)
 	dashboard := r.Group("/dashboard")
-	dashboard.Use(middleware.MasterKeyAuth(cfg.MasterAPIKey))
-	transport.RegisterDashboardRoutes(dashboard, logService)
+	dashboard.Use(authFailureLimit, middleware.AdminOrAPIKeyAuth(adminService, apiKeyService, sessionService), middleware.CSRFProtection(), rateLimit)
+	transport.RegisterDashboardRoutes(dashboard, logService, webhookService)
+
+	// Event ingestion routes (require an operator admin key or a notifications:publish key)
+	events := r.Group("/events")
+	events.Use(authFailureLimit, middleware.AdminOrAPIKeyAuth(adminService, apiKeyService, sessionService), middleware.CSRFProtection(), rateLimit)
+	transport.RegisterEventRoutes(events, notificationService)
 
 	// Protected API routes (require regular API key)
 	api := r.Group("/")
-	api.Use(middleware.APIKeyAuth(apiKeyService))
-	transport.RegisterRoutes(api, db)
+	api.Use(authFailureLimit, middleware.APIKeyAuth(apiKeyService, sessionService), middleware.CSRFProtection(), rateLimit)
+	transport.RegisterRoutes(api, db, bookmarkService, notificationService, webhookService, digestService, pushService, cfg.WebSocketAllowedOrigins)
 
 	return r
 }
+
+func newRateLimiter(cfg config.Config, db *database.DB) ratelimit.Limiter {
+	switch cfg.RateLimitBackend {
+	case "postgres":
+		return ratelimit.NewPostgresLimiter(db)
+	case "memory":
+	default:
+		log.Printf("Unknown RATE_LIMIT_BACKEND %q, using memory", cfg.RateLimitBackend)
+	}
+	return ratelimit.NewMemoryLimiter()
+}
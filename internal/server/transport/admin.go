@@ -0,0 +1,178 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/middleware"
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+	"fandom/notifications/internal/service"
+)
+
+type AdminHandler struct {
+	service *service.AdminService
+}
+
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{service: adminService}
+}
+
+// CreateAdmin godoc
+// @Summary      Create an admin
+// @Description  Creates an admin account with role viewer (read the dashboard, logs and webhook deliveries), operator (also retry deliveries and publish events) or owner (also manage API keys and admins). The admin's key is only shown in this response. Requires the owner role.
+// @Tags         admins
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        request  body      models.CreateAdminRequest  true  "Admin"
+// @Success      201      {object}  models.CreateAdminResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admins [post]
+func (h *AdminHandler) CreateAdmin(c *gin.Context) {
+	var req models.CreateAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request. 'name' is required and 'role' must be one of viewer, operator, owner.",
+		})
+		return
+	}
+
+	response, err := h.service.CreateAdmin(c.Request.Context(), req.Name, req.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create admin"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListAdmins godoc
+// @Summary      List admins
+// @Description  Lists every admin account. Keys are identified by their prefix. Requires the owner role.
+// @Tags         admins
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Success      200  {array}   models.Admin
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admins [get]
+func (h *AdminHandler) ListAdmins(c *gin.Context) {
+	admins, err := h.service.ListAdmins(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve admins"})
+		return
+	}
+
+	c.JSON(http.StatusOK, admins)
+}
+
+// CurrentAdmin godoc
+// @Summary      Get the calling admin
+// @Description  Returns the admin account the request was authenticated as, including its role. Requires any admin role.
+// @Tags         admins
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Success      200  {object}  models.Admin
+// @Failure      403  {object}  map[string]string
+// @Router       /admins/me [get]
+func (h *AdminHandler) CurrentAdmin(c *gin.Context) {
+	c.JSON(http.StatusOK, middleware.CurrentAdmin(c))
+}
+
+// UpdateAdmin godoc
+// @Summary      Update an admin
+// @Description  Changes an admin's role or disables it. Omitted fields are left unchanged. At least one active owner must remain. Requires the owner role.
+// @Tags         admins
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id       path      int                        true  "Admin ID"
+// @Param        request  body      models.UpdateAdminRequest  true  "Changes"
+// @Success      200      {object}  models.Admin
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admins/{id} [put]
+func (h *AdminHandler) UpdateAdmin(c *gin.Context) {
+	id, ok := parseIDParam(c, "admin")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. 'role' must be one of viewer, operator, owner."})
+		return
+	}
+
+	admin, err := h.service.UpdateAdmin(c.Request.Context(), id, req.Role, req.Active)
+	if errors.Is(err, repository.ErrLastOwner) {
+		c.JSON(http.StatusConflict, gin.H{"error": "At least one active owner is required"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin"})
+		return
+	}
+
+	if admin == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, admin)
+}
+
+// DeleteAdmin godoc
+// @Summary      Delete an admin
+// @Description  Permanently deletes an admin account. The last active owner cannot be deleted. Requires the owner role.
+// @Tags         admins
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id   path      int  true  "Admin ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admins/{id} [delete]
+func (h *AdminHandler) DeleteAdmin(c *gin.Context) {
+	id, ok := parseIDParam(c, "admin")
+	if !ok {
+		return
+	}
+
+	deleted, err := h.service.DeleteAdmin(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrLastOwner) {
+		c.JSON(http.StatusConflict, gin.H{"error": "At least one active owner is required"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete admin"})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,28 +1,34 @@
 package transport
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
 	"fandom/notifications/internal/service"
 )
 
 type APIKeyHandler struct {
-	service *service.APIKeyService
+	service       *service.APIKeyService
+	rotationGrace time.Duration
 }
 
-func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
-	return &APIKeyHandler{service: apiKeyService}
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService, rotationGrace time.Duration) *APIKeyHandler {
+	return &APIKeyHandler{service: apiKeyService, rotationGrace: rotationGrace}
 }
 
 // CreateAPIKey godoc
 // @Summary      Generate a new API key
-// @Description  Creates a new API key with the provided name. Requires master API key as query parameter 'api'.
+// @Description  Creates a new API key with the provided name and scopes. Without 'scopes' the key can use every route except event ingestion and logs (bookmarks, notifications, webhooks and preferences, read and write). Without 'expires_at' it never expires. Requires the owner admin role.
 // @Tags         api-keys
 // @Accept       json
 // @Produce      json
-// @Param        api      query     string  true  "Master API Key"
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
 // @Param        request  body      models.CreateAPIKeyRequest  true  "API Key Request"
 // @Success      201     {object}  models.CreateAPIKeyResponse
 // @Failure      400     {object}  map[string]string
@@ -38,7 +44,18 @@ func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
 		return
 	}
 
-	response, err := h.service.CreateAPIKey(c.Request.Context(), req.Name)
+	response, err := h.service.CreateAPIKey(c.Request.Context(), req.Name, req.Scopes, req.ExpiresAt)
+	if errors.Is(err, service.ErrInvalidExpiry) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrUnknownScope) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  err.Error(),
+			"scopes": models.APIKeyScopes,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create API key",
@@ -49,3 +66,263 @@ func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
+// ListAPIKeys godoc
+// @Summary      List API keys
+// @Description  Lists all API keys, newest first. Keys are identified by their prefix; the full key is never returned. Requires any admin role.
+// @Tags         api-keys
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Success      200  {array}   models.APIKey
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	apiKeys, err := h.service.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKeys)
+}
+
+// RenameAPIKey godoc
+// @Summary      Rename an API key
+// @Description  Changes the name of an API key. Requires the owner admin role.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id       path      int     true  "API Key ID"
+// @Param        request  body      models.UpdateAPIKeyRequest  true  "New name"
+// @Success      200      {object}  models.APIKey
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /api-keys/{id} [put]
+func (h *APIKeyHandler) RenameAPIKey(c *gin.Context) {
+	id, ok := parseIDParam(c, "API key")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. 'name' field is required."})
+		return
+	}
+
+	apiKey, err := h.service.RenameAPIKey(c.Request.Context(), id, req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename API key"})
+		return
+	}
+
+	if apiKey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKey)
+}
+
+// SetAPIKeyRateLimit godoc
+// @Summary      Set an API key's rate limit
+// @Description  Overrides the server-wide rate limit (RATE_LIMIT_PER_MINUTE / RATE_LIMIT_BURST) for one key. Omitted or null fields fall back to the defaults, so an empty body resets the key. Requires the owner admin role.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id       path      int     true  "API Key ID"
+// @Param        request  body      models.UpdateAPIKeyRateLimitRequest  true  "Rate limit"
+// @Success      200      {object}  models.APIKey
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /api-keys/{id}/rate-limit [put]
+func (h *APIKeyHandler) SetAPIKeyRateLimit(c *gin.Context) {
+	id, ok := parseIDParam(c, "API key")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAPIKeyRateLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. 'requests_per_minute' and 'burst' must be positive integers or null."})
+		return
+	}
+
+	apiKey, err := h.service.SetAPIKeyRateLimit(c.Request.Context(), id, req.RequestsPerMinute, req.Burst)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update API key"})
+		return
+	}
+
+	if apiKey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKey)
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Description  Deactivates an API key; requests using it are rejected until it is reactivated. Requires the owner admin role.
+// @Tags         api-keys
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id   path      int     true  "API Key ID"
+// @Success      200  {object}  models.APIKey
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api-keys/{id}/revoke [post]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+// ReactivateAPIKey godoc
+// @Summary      Reactivate an API key
+// @Description  Re-enables a revoked API key. Requires the owner admin role.
+// @Tags         api-keys
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id   path      int     true  "API Key ID"
+// @Success      200  {object}  models.APIKey
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api-keys/{id}/reactivate [post]
+func (h *APIKeyHandler) ReactivateAPIKey(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+func (h *APIKeyHandler) setActive(c *gin.Context, active bool) {
+	id, ok := parseIDParam(c, "API key")
+	if !ok {
+		return
+	}
+
+	var apiKey *models.APIKey
+	var err error
+	if active {
+		apiKey, err = h.service.ReactivateAPIKey(c.Request.Context(), id)
+	} else {
+		apiKey, err = h.service.RevokeAPIKey(c.Request.Context(), id)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update API key"})
+		return
+	}
+
+	if apiKey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKey)
+}
+
+// RotateAPIKey godoc
+// @Summary      Rotate an API key
+// @Description  Issues a successor key with the same name and scopes and moves the old key's webhooks to it. The old key keeps working for grace_period_seconds (default API_KEY_ROTATION_GRACE) so clients can switch without downtime. The new key is only shown in this response. Requires the owner admin role.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id       path      int     true  "API Key ID"
+// @Param        request  body      models.RotateAPIKeyRequest  false  "Grace period"
+// @Success      201      {object}  models.RotateAPIKeyResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	id, ok := parseIDParam(c, "API key")
+	if !ok {
+		return
+	}
+
+	var req models.RotateAPIKeyRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. 'grace_period_seconds' must be a non-negative integer."})
+			return
+		}
+	}
+
+	grace := h.rotationGrace
+	if req.GracePeriodSeconds != nil {
+		grace = time.Duration(*req.GracePeriodSeconds) * time.Second
+	}
+
+	response, err := h.service.RotateAPIKey(c.Request.Context(), id, grace)
+	if errors.Is(err, repository.ErrAPIKeyInactive) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Revoked API keys cannot be rotated"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate API key"})
+		return
+	}
+
+	if response == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// DeleteAPIKey godoc
+// @Summary      Delete an API key
+// @Description  Permanently deletes an API key and its webhook subscriptions. Prefer revoking if the key may be needed again. Requires the owner admin role.
+// @Tags         api-keys
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id   path      int     true  "API Key ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api-keys/{id} [delete]
+func (h *APIKeyHandler) DeleteAPIKey(c *gin.Context) {
+	id, ok := parseIDParam(c, "API key")
+	if !ok {
+		return
+	}
+
+	deleted, err := h.service.DeleteAPIKey(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete API key"})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
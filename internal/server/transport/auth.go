@@ -1,23 +1,57 @@
 package transport
 
 import (
+	"crypto/subtle"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/middleware"
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/service"
 )
 
+// legacyAPIKeyCookie held the raw key before sessions existed. It is no
+// longer read, only cleared on logout.
+const legacyAPIKeyCookie = "api_key"
+
+// loginCSRFCookie holds the double-submit CSRF token of the /auth page, which
+// protects logins and logouts made before or outside a session.
+const loginCSRFCookie = "csrf_token"
+
+type AuthHandler struct {
+	sessions     *service.SessionService
+	secureCookie bool
+}
+
+func NewAuthHandler(sessionService *service.SessionService, secureCookie bool) *AuthHandler {
+	return &AuthHandler{sessions: sessionService, secureCookie: secureCookie}
+}
+
 // AuthPage godoc
 // @Summary      Authentication page
-// @Description  HTML page for entering API key
+// @Description  HTML page for entering API key. Sets the CSRF cookie that /auth/set and /auth/clear check.
 // @Tags         auth
 // @Produce      html
+// @Failure      500     {object}  map[string]string
 // @Router       /auth [get]
-func AuthPage(c *gin.Context) {
+func (h *AuthHandler) AuthPage(c *gin.Context) {
+	csrfToken, err := service.NewCSRFToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate CSRF token"})
+		return
+	}
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(loginCSRFCookie, csrfToken, 0, "/auth", "", h.secureCookie, true)
+
 	html := `<!DOCTYPE html>
 <html lang="en">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <meta name="csrf-token" content="` + csrfToken + `">
     <title>API Key Authentication</title>
     <style>
         * { margin: 0; padding: 0; box-sizing: border-box; }
@@ -159,23 +193,13 @@ func AuthPage(c *gin.Context) {
         
         <div class="info">
             <strong>ℹ️ Information:</strong>
-            Your API key will be stored in a secure cookie and used for all subsequent requests.
-            You can clear it at any time using the Clear button.
+            Your key is checked and exchanged for a session; only the session is stored in a cookie.
+            Use the Clear button to end the session.
         </div>
     </div>
 
     <script>
-        // Check if already authenticated
-        window.onload = function() {
-            const cookies = document.cookie.split(';');
-            const apiKeyCookie = cookies.find(c => c.trim().startsWith('api_key='));
-            if (apiKeyCookie) {
-                showMessage('You are already authenticated. Redirecting to dashboard...', 'success');
-                setTimeout(() => {
-                    window.location.href = '/dashboard';
-                }, 1500);
-            }
-        };
+        const csrfToken = document.querySelector('meta[name="csrf-token"]').content;
 
         function submitAuth() {
             const apiKey = document.getElementById('apiKey').value.trim();
@@ -188,6 +212,7 @@ func AuthPage(c *gin.Context) {
                 method: 'POST',
                 headers: {
                     'Content-Type': 'application/json',
+                    'X-CSRF-Token': csrfToken,
                 },
                 body: JSON.stringify({ api_key: apiKey })
             })
@@ -210,7 +235,8 @@ func AuthPage(c *gin.Context) {
 
         function clearAuth() {
             fetch('/auth/clear', {
-                method: 'POST'
+                method: 'POST',
+                headers: { 'X-CSRF-Token': csrfToken }
             })
             .then(() => {
                 document.getElementById('apiKey').value = '';
@@ -240,20 +266,28 @@ func AuthPage(c *gin.Context) {
 }
 
 // SetAuthCookie godoc
-// @Summary      Set API key cookie
-// @Description  Stores the provided API key in a secure cookie
+// @Summary      Log in
+// @Description  Checks the admin or API key and starts a server-side session. The response sets an HttpOnly, SameSite=Lax 'session' cookie holding an opaque token; the key itself is not stored in the browser. The session ends on /auth/clear, after SESSION_TTL, or when the key is revoked or the admin disabled. Requires the CSRF token of the /auth page in X-CSRF-Token; the response carries the session's own CSRF token for later writes.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
-// @Param        request  body      map[string]string  true  "API Key"
+// @Param        X-CSRF-Token  header    string                       true  "CSRF token from the /auth page"
+// @Param        request  body      models.CreateSessionRequest  true  "API or admin key"
 // @Success      200     {object}  map[string]interface{}
 // @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
 // @Router       /auth/set [post]
-func SetAuthCookie(c *gin.Context) {
-	var req struct {
-		APIKey string `json:"api_key" binding:"required"`
+func (h *AuthHandler) SetAuthCookie(c *gin.Context) {
+	if !h.validLoginCSRFToken(c) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "Missing or invalid CSRF token. Reload the /auth page and try again.",
+		})
+		return
 	}
 
+	var req models.CreateSessionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
@@ -262,45 +296,107 @@ func SetAuthCookie(c *gin.Context) {
 		return
 	}
 
-	// Set secure HTTP-only cookie (valid for 7 days)
-	c.SetCookie(
-		"api_key",
-		req.APIKey,
-		7*24*60*60, // 7 days in seconds
-		"/",
-		"",
-		false, // Set to true in production with HTTPS
-		true,  // HttpOnly
-	)
+	token, session, err := h.sessions.Login(c.Request.Context(), req.APIKey, c.Request.UserAgent(), c.ClientIP())
+	if errors.Is(err, service.ErrInvalidCredentials) || errors.Is(err, service.ErrAPIKeyExpired) {
+		middleware.MarkAuthFailed(c)
+	}
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "Invalid or inactive key",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrAPIKeyExpired) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "API key has expired",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to start session",
+		})
+		return
+	}
+
+	h.setSessionCookie(c, token, int(time.Until(session.ExpiresAt).Seconds()))
+	// Drop the raw key left behind by older versions
+	h.clearCookie(c, legacyAPIKeyCookie)
 
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "API key stored successfully",
+		"success":    true,
+		"message":    "Session started",
+		"expires_at": session.ExpiresAt,
+		"csrf_token": service.CSRFToken(token),
 	})
 }
 
 // ClearAuthCookie godoc
-// @Summary      Clear API key cookie
-// @Description  Removes the API key cookie
+// @Summary      Log out
+// @Description  Revokes the current session server-side and clears its cookie. With a session cookie, requires the session's CSRF token or the one from the /auth page in X-CSRF-Token.
 // @Tags         auth
 // @Produce      json
+// @Param        X-CSRF-Token  header    string  false  "CSRF token of the session or the /auth page"
 // @Success      200     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
 // @Router       /auth/clear [post]
-func ClearAuthCookie(c *gin.Context) {
-	// Clear the cookie by setting it to expire in the past
+func (h *AuthHandler) ClearAuthCookie(c *gin.Context) {
+	if token, err := c.Cookie(middleware.SessionCookieName); err == nil && token != "" {
+		if !service.ValidCSRFToken(token, c.GetHeader(middleware.CSRFHeader)) && !h.validLoginCSRFToken(c) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Missing or invalid CSRF token",
+			})
+			return
+		}
+
+		if err := h.sessions.Logout(c.Request.Context(), token); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to end session",
+			})
+			return
+		}
+	}
+
+	h.clearCookie(c, middleware.SessionCookieName)
+	h.clearCookie(c, legacyAPIKeyCookie)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Session ended",
+	})
+}
+
+func (h *AuthHandler) setSessionCookie(c *gin.Context, token string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
 	c.SetCookie(
-		"api_key",
-		"",
-		-1,
+		middleware.SessionCookieName,
+		token,
+		maxAge,
 		"/",
 		"",
-		false,
-		true,
+		h.secureCookie,
+		true, // HttpOnly
 	)
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "API key cleared successfully",
-	})
+// validLoginCSRFToken reports whether the X-CSRF-Token header matches the
+// CSRF cookie set by the /auth page. Other sites can neither read the cookie
+// nor set the header, so a match means the request came from that page.
+func (h *AuthHandler) validLoginCSRFToken(c *gin.Context) bool {
+	cookie, err := c.Cookie(loginCSRFCookie)
+	if err != nil || cookie == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(c.GetHeader(middleware.CSRFHeader))) == 1
 }
 
+func (h *AuthHandler) clearCookie(c *gin.Context, name string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(name, "", -1, "/", "", h.secureCookie, true)
+}
@@ -0,0 +1,265 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+	"fandom/notifications/internal/service"
+)
+
+type BookmarkHandler struct {
+	service *service.BookmarkService
+}
+
+func NewBookmarkHandler(bookmarkService *service.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{service: bookmarkService}
+}
+
+// CreateBookmark godoc
+// @Summary      Create a bookmark
+// @Description  Creates a bookmark for a user and publication. Requires an API key.
+// @Tags         bookmarks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        request  body      models.CreateBookmarkRequest  true  "Bookmark"
+// @Success      201     {object}  models.Bookmark
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      409     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /bookmarks [post]
+func (h *BookmarkHandler) CreateBookmark(c *gin.Context) {
+	var req models.CreateBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request. 'user_id', 'publication_id' and 'chapter_id' fields are required.",
+		})
+		return
+	}
+
+	bookmark, err := h.service.CreateBookmark(c.Request.Context(), req)
+	if errors.Is(err, repository.ErrBookmarkExists) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "A bookmark already exists for this user and publication. Use PUT /bookmarks/position to move it.",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bookmark"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, bookmark)
+}
+
+// SetReadingPosition godoc
+// @Summary      Set reading position
+// @Description  Creates or moves the user's single bookmark for a publication and reports whether it was created or moved forward or backward. Requires an API key.
+// @Tags         bookmarks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        request  body      models.UpsertBookmarkRequest  true  "Reading position"
+// @Success      200     {object}  models.UpsertBookmarkResponse
+// @Success      201     {object}  models.UpsertBookmarkResponse
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /bookmarks/position [put]
+func (h *BookmarkHandler) SetReadingPosition(c *gin.Context) {
+	var req models.UpsertBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request. 'user_id', 'publication_id' and 'chapter_id' fields are required.",
+		})
+		return
+	}
+
+	response, err := h.service.SetReadingPosition(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set reading position"})
+		return
+	}
+
+	status := http.StatusOK
+	if response.Movement == models.BookmarkCreated {
+		status = http.StatusCreated
+	}
+
+	c.JSON(status, response)
+}
+
+// ListBookmarks godoc
+// @Summary      List bookmarks
+// @Description  Retrieve paginated bookmarks with optional user and publication filters. Requires an API key.
+// @Tags         bookmarks
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        limit           query     int     false  "Limit (max 1000)"
+// @Param        offset          query     int     false  "Offset"
+// @Param        page            query     int     false  "Page (overrides offset)"
+// @Param        user_id         query     string  false  "Filter by user ID"
+// @Param        publication_id  query     string  false  "Filter by publication ID"
+// @Success      200             {object}  map[string]interface{}
+// @Failure      400             {object}  map[string]string
+// @Failure      403             {object}  map[string]string
+// @Failure      500             {object}  map[string]string
+// @Router       /bookmarks [get]
+func (h *BookmarkHandler) ListBookmarks(c *gin.Context) {
+	var params models.BookmarkQueryParams
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = 100
+	}
+
+	if params.Page > 0 {
+		params.Offset = (params.Page - 1) * params.Limit
+	}
+
+	bookmarks, total, err := h.service.ListBookmarks(c.Request.Context(), params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bookmarks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bookmarks": bookmarks,
+		"total":     total,
+		"limit":     params.Limit,
+		"offset":    params.Offset,
+		"page":      params.Page,
+	})
+}
+
+// GetBookmark godoc
+// @Summary      Get a bookmark
+// @Description  Fetch a single bookmark by ID. Requires an API key.
+// @Tags         bookmarks
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id   path      int     true  "Bookmark ID"
+// @Success      200  {object}  models.Bookmark
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /bookmarks/{id} [get]
+func (h *BookmarkHandler) GetBookmark(c *gin.Context) {
+	id, ok := parseIDParam(c, "bookmark")
+	if !ok {
+		return
+	}
+
+	bookmark, err := h.service.GetBookmark(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bookmark"})
+		return
+	}
+
+	if bookmark == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bookmark not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, bookmark)
+}
+
+// UpdateBookmark godoc
+// @Summary      Update a bookmark
+// @Description  Updates the provided fields of a bookmark. Omitted fields are left unchanged. Requires an API key.
+// @Tags         bookmarks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id       path      int     true  "Bookmark ID"
+// @Param        request  body      models.UpdateBookmarkRequest  true  "Fields to update"
+// @Success      200     {object}  models.Bookmark
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /bookmarks/{id} [put]
+func (h *BookmarkHandler) UpdateBookmark(c *gin.Context) {
+	id, ok := parseIDParam(c, "bookmark")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.ChapterID != nil && *req.ChapterID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'chapter_id' cannot be empty"})
+		return
+	}
+
+	bookmark, err := h.service.UpdateBookmark(c.Request.Context(), id, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bookmark"})
+		return
+	}
+
+	if bookmark == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bookmark not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, bookmark)
+}
+
+// DeleteBookmark godoc
+// @Summary      Delete a bookmark
+// @Description  Deletes a bookmark by ID. Requires an API key.
+// @Tags         bookmarks
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id   path      int     true  "Bookmark ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /bookmarks/{id} [delete]
+func (h *BookmarkHandler) DeleteBookmark(c *gin.Context) {
+	id, ok := parseIDParam(c, "bookmark")
+	if !ok {
+		return
+	}
+
+	deleted, err := h.service.DeleteBookmark(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bookmark"})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bookmark not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
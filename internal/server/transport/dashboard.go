@@ -1,10 +1,12 @@
 package transport
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"fandom/notifications/internal/middleware"
 	"fandom/notifications/internal/models"
 	"fandom/notifications/internal/service"
 
@@ -12,11 +14,12 @@ import (
 )
 
 type DashboardHandler struct {
-	logService *service.LogService
+	logService     *service.LogService
+	webhookService *service.WebhookService
 }
 
-func NewDashboardHandler(logService *service.LogService) *DashboardHandler {
-	return &DashboardHandler{logService: logService}
+func NewDashboardHandler(logService *service.LogService, webhookService *service.WebhookService) *DashboardHandler {
+	return &DashboardHandler{logService: logService, webhookService: webhookService}
 }
 
 // GetLogs godoc
@@ -24,6 +27,9 @@ func NewDashboardHandler(logService *service.LogService) *DashboardHandler {
 // @Description  Retrieve paginated request logs with optional filters
 // @Tags         dashboard
 // @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
 // @Param        limit       query     int     false  "Limit (max 1000)"
 // @Param        offset      query     int     false  "Offset"
 // @Param        method      query     string  false  "Filter by HTTP method"
@@ -67,12 +73,17 @@ func (h *DashboardHandler) GetLogs(c *gin.Context) {
 
 // GetStats godoc
 // @Summary      Get log statistics
-// @Description  Get aggregated statistics about request logs
+// @Description  Get aggregated statistics about request logs: totals, average and p50/p90/p99 response times, the busiest paths with their percentiles, and a series of request, 4xx and 5xx counts per bucket for charting
 // @Tags         dashboard
 // @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
 // @Param        start_date  query     string  false  "Start date (RFC3339)"
 // @Param        end_date    query     string  false  "End date (RFC3339)"
+// @Param        bucket      query     string  false  "Series bucket: minute, hour or day (default hour; day for ranges over 31 days)"
 // @Success      200         {object}  models.LogStats
+// @Failure      400         {object}  map[string]string
 // @Failure      500         {object}  map[string]string
 // @Router       /dashboard/stats [get]
 func (h *DashboardHandler) GetStats(c *gin.Context) {
@@ -95,7 +106,11 @@ func (h *DashboardHandler) GetStats(c *gin.Context) {
 		}
 	}
 
-	stats, err := h.logService.GetStats(c.Request.Context(), startDate, endDate)
+	stats, err := h.logService.GetStats(c.Request.Context(), startDate, endDate, c.Query("bucket"))
+	if errors.Is(err, service.ErrInvalidStatsBucket) || errors.Is(err, service.ErrTooManyStatsBuckets) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve statistics"})
 		return
@@ -104,15 +119,36 @@ func (h *DashboardHandler) GetStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetLogPartitions godoc
+// @Summary      Request log partitions
+// @Description  List the request_logs partitions with their bounds, on-disk size and estimated row count, plus the partition interval and retention period. Requires any admin role.
+// @Tags         dashboard
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Success      200  {object}  models.LogPartitionsResponse
+// @Failure      500  {object}  map[string]string
+// @Router       /dashboard/logs/partitions [get]
+func (h *DashboardHandler) GetLogPartitions(c *gin.Context) {
+	partitions, err := h.logService.GetPartitions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve log partitions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, partitions)
+}
+
 // DashboardPage godoc
 // @Summary      Dashboard HTML page
-// @Description  Serve the dashboard HTML page. Requires authentication via cookie or query parameter.
+// @Description  Serve the dashboard HTML page. Requires any admin role; without a key the browser is redirected to /auth.
 // @Tags         dashboard
 // @Produce      html
 // @Router       /dashboard [get]
 func (h *DashboardHandler) DashboardPage(c *gin.Context) {
 	// Get default stats for the page
-	stats, _ := h.logService.GetStats(c.Request.Context(), time.Time{}, time.Time{})
+	stats, _ := h.logService.GetStats(c.Request.Context(), time.Time{}, time.Time{}, "")
 
 	// Get recent logs
 	params := models.LogQueryParams{
@@ -121,16 +157,19 @@ func (h *DashboardHandler) DashboardPage(c *gin.Context) {
 	}
 	logs, total, _ := h.logService.GetLogs(c.Request.Context(), params)
 
-	html := generateDashboardHTML(stats, logs, total)
+	html := generateDashboardHTML(stats, logs, total, middleware.CSRFToken(c))
 	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
 }
 
-func generateDashboardHTML(stats *models.LogStats, logs []models.RequestLog, total int64) string {
+// generateDashboardHTML renders the page. csrfToken is sent back with every
+// write; it is empty when the page was opened with a key instead of a session.
+func generateDashboardHTML(stats *models.LogStats, logs []models.RequestLog, total int64, csrfToken string) string {
 	html := `<!DOCTYPE html>
 <html lang="en">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <meta name="csrf-token" content="` + csrfToken + `">
     <title>Request Logs Dashboard</title>
     <style>
         * { margin: 0; padding: 0; box-sizing: border-box; }
@@ -164,6 +203,34 @@ func generateDashboardHTML(stats *models.LogStats, logs []models.RequestLog, tot
             font-weight: bold;
             color: #333;
         }
+        .chart {
+            background: white;
+            padding: 20px;
+            border-radius: 8px;
+            margin-bottom: 30px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+        }
+        .chart h3 {
+            color: #666;
+            font-size: 14px;
+            margin-bottom: 10px;
+            text-transform: uppercase;
+        }
+        .chart-bars {
+            display: flex;
+            align-items: flex-end;
+            gap: 1px;
+            height: 120px;
+        }
+        .chart-bar {
+            flex: 1;
+            display: flex;
+            flex-direction: column;
+            justify-content: flex-end;
+            background: #667eea;
+            min-height: 1px;
+        }
+        .chart-bar .errors { background: #dc3545; }
         .filters {
             background: white;
             padding: 20px;
@@ -232,6 +299,10 @@ func generateDashboardHTML(stats *models.LogStats, logs []models.RequestLog, tot
             justify-content: space-between;
             align-items: center;
         }
+        .section-title {
+            color: #333;
+            margin: 30px 0 15px;
+        }
         .refresh-btn {
             padding: 8px 16px;
             background: #28a745;
@@ -255,6 +326,15 @@ func generateDashboardHTML(stats *models.LogStats, logs []models.RequestLog, tot
                 <h3>Avg Response Time</h3>
                 <div class="value">` + formatFloat(stats.AverageResponseTime) + `ms</div>
             </div>
+            <div class="stat-card">
+                <h3>p50 / p90 / p99</h3>
+                <div class="value">` + formatLatency(stats.Latency.P50) + ` / ` + formatLatency(stats.Latency.P90) + ` / ` + formatLatency(stats.Latency.P99) + `ms</div>
+            </div>
+        </div>
+
+        <div class="chart">
+            <h3>Requests per ` + stats.Bucket + ` (5xx in red)</h3>
+            <div class="chart-bars">` + generateSeriesBars(stats.Series) + `</div>
         </div>
 
         <div class="filters">
@@ -266,7 +346,9 @@ func generateDashboardHTML(stats *models.LogStats, logs []models.RequestLog, tot
                 <input type="datetime-local" name="end_date" id="end_date">
                 <button type="submit">Filter</button>
                 <button type="button" onclick="clearFilters()">Clear</button>
-                <button type="button" onclick="window.location.href='/auth'" style="background: #dc3545;">Logout</button>
+                <button type="button" onclick="exportLogs('csv')">Export CSV</button>
+                <button type="button" onclick="exportLogs('ndjson')">Export NDJSON</button>
+                <button type="button" onclick="logout()" style="background: #dc3545;">Logout</button>
             </form>
         </div>
 
@@ -295,12 +377,72 @@ func generateDashboardHTML(stats *models.LogStats, logs []models.RequestLog, tot
                 <button class="refresh-btn" onclick="loadLogs()">🔄 Refresh</button>
             </div>
         </div>
+
+        <h2 class="section-title">🔑 API Keys</h2>
+        <div class="logs-table">
+            <table>
+                <thead>
+                    <tr>
+                        <th>Key</th>
+                        <th>Name</th>
+                        <th>Scopes</th>
+                        <th>Status</th>
+                        <th>Expires</th>
+                        <th>Created</th>
+                        <th>Last Used</th>
+                        <th></th>
+                    </tr>
+                </thead>
+                <tbody id="apiKeyTableBody">
+                    <tr><td colspan="8" style="text-align: center; padding: 20px;">Loading...</td></tr>
+                </tbody>
+            </table>
+            <div class="pagination">
+                <div></div>
+                <button class="refresh-btn" onclick="loadAPIKeys()">🔄 Refresh</button>
+            </div>
+        </div>
+
+        <h2 class="section-title">🪝 Webhook Deliveries</h2>
+        <div class="logs-table">
+            <table>
+                <thead>
+                    <tr>
+                        <th>Created</th>
+                        <th>Event</th>
+                        <th>URL</th>
+                        <th>Status</th>
+                        <th>Attempts</th>
+                        <th>Next Attempt</th>
+                        <th>Last Error</th>
+                        <th></th>
+                    </tr>
+                </thead>
+                <tbody id="webhookTableBody">
+                    <tr><td colspan="8" style="text-align: center; padding: 20px;">Loading...</td></tr>
+                </tbody>
+            </table>
+            <div class="pagination">
+                <select id="webhookStatus" onchange="loadWebhookDeliveries()">
+                    <option value="">All statuses</option>
+                    <option value="pending">Pending</option>
+                    <option value="delivered">Delivered</option>
+                    <option value="dead">Dead</option>
+                </select>
+                <button class="refresh-btn" onclick="loadWebhookDeliveries()">🔄 Refresh</button>
+            </div>
+        </div>
     </div>
 
     <script>
+        // Sent with every write made with the session cookie
+        const csrfToken = document.querySelector('meta[name="csrf-token"]').content;
+
         // Check authentication on page load
         window.onload = function() {
             loadLogs();
+            loadAPIKeys();
+            loadWebhookDeliveries();
         };
 
         function loadPreviousPage() {
@@ -321,7 +463,7 @@ func generateDashboardHTML(stats *models.LogStats, logs []models.RequestLog, tot
             loadLogs();
         }
 
-        function loadLogs() {
+        function filterParams() {
             const params = new URLSearchParams(location.search);
             const method = document.getElementById('method').value;
             const statusCode = document.getElementById('status_code').value;
@@ -334,6 +476,11 @@ func generateDashboardHTML(stats *models.LogStats, logs []models.RequestLog, tot
             if (path) params.append('path', path);
             if (startDate) params.append('start_date', new Date(startDate).toISOString());
             if (endDate) params.append('end_date', new Date(endDate).toISOString());
+            return params;
+        }
+
+        function loadLogs() {
+            const params = filterParams();
 
             fetch('/dashboard/logs?' + params.toString(), {
                 credentials: 'include'
@@ -374,6 +521,17 @@ func generateDashboardHTML(stats *models.LogStats, logs []models.RequestLog, tot
                 });
         }
 
+        function exportLogs(format) {
+            const params = filterParams();
+            params.set('format', format);
+            window.location.href = '/dashboard/logs/export?' + params.toString();
+        }
+
+        function logout() {
+            fetch('/auth/clear', { method: 'POST', headers: { 'X-CSRF-Token': csrfToken } })
+                .finally(() => { window.location.href = '/auth'; });
+        }
+
         function clearFilters() {
             document.getElementById('filterForm').reset();
             loadLogs();
@@ -386,8 +544,177 @@ func generateDashboardHTML(stats *models.LogStats, logs []models.RequestLog, tot
             return '5xx';
         }
 
+        function escapeHTML(value) {
+            return String(value == null ? '' : value)
+                .replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;')
+                .replace(/"/g, '&quot;').replace(/'/g, '&#39;');
+        }
+
+        function loadAPIKeys() {
+            fetch('/api-keys', {
+                credentials: 'include'
+            })
+                .then(r => {
+                    if (!r.ok) {
+                        throw new Error('Failed to load API keys');
+                    }
+                    return r.json();
+                })
+                .then(keys => {
+                    const tbody = document.getElementById('apiKeyTableBody');
+                    if (keys.length > 0) {
+                        tbody.innerHTML = keys.map(k => {
+                            const toggle = k.active
+                                ? '<button onclick="updateAPIKey(' + k.id + ', \'revoke\')">Revoke</button>'
+                                : '<button onclick="updateAPIKey(' + k.id + ', \'reactivate\')">Reactivate</button>';
+                            return '<tr>' +
+                                '<td class="response-time">' + escapeHTML(k.prefix) + '…</td>' +
+                                '<td>' + escapeHTML(k.name) + '</td>' +
+                                '<td>' + escapeHTML((k.scopes || []).join(', ')) + '</td>' +
+                                '<td>' + apiKeyStatus(k) + '</td>' +
+                                '<td>' + (k.expires_at ? new Date(k.expires_at).toLocaleString() : 'Never') + '</td>' +
+                                '<td>' + new Date(k.created_at).toLocaleString() + '</td>' +
+                                '<td>' + (k.last_used_at ? new Date(k.last_used_at).toLocaleString() : 'Never') + '</td>' +
+                                '<td>' +
+                                    '<button onclick="renameAPIKey(' + k.id + ')">Rename</button> ' +
+                                    (k.active ? '<button onclick="rotateAPIKey(' + k.id + ')">Rotate</button> ' : '') +
+                                    toggle + ' ' +
+                                    '<button onclick="deleteAPIKey(' + k.id + ')">Delete</button>' +
+                                '</td>' +
+                                '</tr>';
+                        }).join('');
+                    } else {
+                        tbody.innerHTML = '<tr><td colspan="8" style="text-align: center; padding: 20px;">No API keys</td></tr>';
+                    }
+                })
+                .catch(err => {
+                    console.error('Error loading API keys:', err);
+                    const tbody = document.getElementById('apiKeyTableBody');
+                    tbody.innerHTML = '<tr><td colspan="8" style="text-align: center; padding: 20px; color: #dc3545;">Error loading API keys.</td></tr>';
+                });
+        }
+
+        function apiKeyStatus(k) {
+            if (!k.active) return 'Revoked';
+            if (k.expires_at && new Date(k.expires_at) <= new Date()) return 'Expired';
+            if (k.replaced_by_id) return 'Rotated';
+            return 'Active';
+        }
+
+        function rotateAPIKey(id) {
+            const grace = prompt('Keep the old key working for how many hours?', '24');
+            if (grace === null) return;
+
+            fetch('/api-keys/' + id + '/rotate', {
+                method: 'POST',
+                credentials: 'include',
+                headers: { 'Content-Type': 'application/json', 'X-CSRF-Token': csrfToken },
+                body: JSON.stringify({ grace_period_seconds: Math.round(Number(grace) * 3600) })
+            })
+                .then(r => r.json())
+                .then(data => {
+                    if (data.key) {
+                        prompt('New API key (shown only once):', data.key);
+                    } else {
+                        alert(data.error || 'Failed to rotate API key');
+                    }
+                    loadAPIKeys();
+                })
+                .catch(err => console.error('Error rotating API key:', err));
+        }
+
+        function updateAPIKey(id, action) {
+            fetch('/api-keys/' + id + '/' + action, {
+                method: 'POST',
+                credentials: 'include',
+                headers: { 'X-CSRF-Token': csrfToken }
+            })
+                .then(() => loadAPIKeys())
+                .catch(err => console.error('Error updating API key:', err));
+        }
+
+        function renameAPIKey(id) {
+            const name = prompt('New name for this API key:');
+            if (!name) return;
+
+            fetch('/api-keys/' + id, {
+                method: 'PUT',
+                credentials: 'include',
+                headers: { 'Content-Type': 'application/json', 'X-CSRF-Token': csrfToken },
+                body: JSON.stringify({ name: name })
+            })
+                .then(() => loadAPIKeys())
+                .catch(err => console.error('Error renaming API key:', err));
+        }
+
+        function deleteAPIKey(id) {
+            if (!confirm('Delete this API key permanently? Clients using it will stop working.')) return;
+
+            fetch('/api-keys/' + id, {
+                method: 'DELETE',
+                credentials: 'include',
+                headers: { 'X-CSRF-Token': csrfToken }
+            })
+                .then(() => loadAPIKeys())
+                .catch(err => console.error('Error deleting API key:', err));
+        }
+
+        function loadWebhookDeliveries() {
+            const params = new URLSearchParams({ limit: '20' });
+            const status = document.getElementById('webhookStatus').value;
+            if (status) params.append('status', status);
+
+            fetch('/dashboard/webhooks/deliveries?' + params.toString(), {
+                credentials: 'include'
+            })
+                .then(r => {
+                    if (!r.ok) {
+                        throw new Error('Failed to load webhook deliveries');
+                    }
+                    return r.json();
+                })
+                .then(data => {
+                    const tbody = document.getElementById('webhookTableBody');
+                    if (data.deliveries && data.deliveries.length > 0) {
+                        tbody.innerHTML = data.deliveries.map(d => {
+                            const retry = d.status === 'dead'
+                                ? '<button onclick="retryWebhookDelivery(' + d.id + ')">Retry</button>'
+                                : '';
+                            return '<tr>' +
+                                '<td>' + new Date(d.created_at).toLocaleString() + '</td>' +
+                                '<td>' + escapeHTML(d.event_type) + '</td>' +
+                                '<td>' + escapeHTML(d.url) + '</td>' +
+                                '<td>' + escapeHTML(d.status) + '</td>' +
+                                '<td>' + d.attempts + '</td>' +
+                                '<td>' + (d.status === 'pending' ? new Date(d.next_attempt_at).toLocaleString() : '') + '</td>' +
+                                '<td>' + escapeHTML(d.last_error) + '</td>' +
+                                '<td>' + retry + '</td>' +
+                                '</tr>';
+                        }).join('');
+                    } else {
+                        tbody.innerHTML = '<tr><td colspan="8" style="text-align: center; padding: 20px;">No webhook deliveries</td></tr>';
+                    }
+                })
+                .catch(err => {
+                    console.error('Error loading webhook deliveries:', err);
+                    const tbody = document.getElementById('webhookTableBody');
+                    tbody.innerHTML = '<tr><td colspan="8" style="text-align: center; padding: 20px; color: #dc3545;">Error loading webhook deliveries.</td></tr>';
+                });
+        }
+
+        function retryWebhookDelivery(id) {
+            fetch('/dashboard/webhooks/deliveries/' + id + '/retry', {
+                method: 'POST',
+                credentials: 'include',
+                headers: { 'X-CSRF-Token': csrfToken }
+            })
+                .then(() => loadWebhookDeliveries())
+                .catch(err => console.error('Error retrying webhook delivery:', err));
+        }
+
         // Auto-refresh every 30 seconds
         setInterval(loadLogs, 30000);
+        setInterval(loadWebhookDeliveries, 30000);
     </script>
 </body>
 </html>`
@@ -418,6 +745,35 @@ func generateLogsTableRows(logs []models.RequestLog) string {
 	return rows
 }
 
+// generateSeriesBars renders one bar per bucket, scaled to the busiest
+// bucket, with its 5xx share in red.
+func generateSeriesBars(series []models.StatsBucket) string {
+	var peak int64
+	for _, bucket := range series {
+		peak = max(peak, bucket.Requests)
+	}
+
+	bars := ""
+	for _, bucket := range series {
+		height, errorHeight := 0.0, 0.0
+		if peak > 0 {
+			height = float64(bucket.Requests) / float64(peak) * 100
+		}
+		if bucket.Requests > 0 {
+			errorHeight = bucket.ErrorRate * 100
+		}
+
+		title := bucket.Start.Format("2006-01-02 15:04") + ": " + formatNumber(bucket.Requests) + " requests, " + formatNumber(bucket.ServerErrors) + " 5xx"
+		bars += `<div class="chart-bar" style="height: ` + formatFloat(height) + `%" title="` + title + `">` +
+			`<div class="errors" style="height: ` + formatFloat(errorHeight) + `%"></div></div>`
+	}
+	return bars
+}
+
+func formatLatency(ms float64) string {
+	return strconv.FormatFloat(ms, 'f', 0, 64)
+}
+
 func formatNumber(n int64) string {
 	return strconv.FormatInt(n, 10)
 }
@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/models"
+)
+
+// GetWebhookDeliveries godoc
+// @Summary      Get webhook deliveries
+// @Description  Retrieve paginated webhook deliveries with optional filters
+// @Tags         dashboard
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        limit            query     int     false  "Limit (max 1000)"
+// @Param        offset           query     int     false  "Offset"
+// @Param        page             query     int     false  "Page (overrides offset)"
+// @Param        status           query     string  false  "Filter by status (pending, delivered, dead)"
+// @Param        subscription_id  query     int     false  "Filter by webhook subscription ID"
+// @Param        event_type       query     string  false  "Filter by event type"
+// @Success      200              {object}  map[string]interface{}
+// @Failure      400              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /dashboard/webhooks/deliveries [get]
+func (h *DashboardHandler) GetWebhookDeliveries(c *gin.Context) {
+	var params models.WebhookDeliveryQueryParams
+
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = 100
+	}
+
+	if params.Page > 0 {
+		params.Offset = (params.Page - 1) * params.Limit
+	}
+
+	deliveries, total, err := h.webhookService.ListDeliveries(c.Request.Context(), params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"total":      total,
+		"limit":      params.Limit,
+		"offset":     params.Offset,
+		"page":       params.Page,
+	})
+}
+
+// GetWebhookDelivery godoc
+// @Summary      Get a webhook delivery
+// @Description  Retrieve a webhook delivery with its payload and every delivery attempt
+// @Tags         dashboard
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id   path      int  true  "Delivery ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /dashboard/webhooks/deliveries/{id} [get]
+func (h *DashboardHandler) GetWebhookDelivery(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	delivery, attempts, err := h.webhookService.GetDelivery(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook delivery"})
+		return
+	}
+
+	if delivery == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook delivery not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"delivery": delivery,
+		"payload":  json.RawMessage(delivery.Payload),
+		"attempts": attempts,
+	})
+}
+
+// RetryWebhookDelivery godoc
+// @Summary      Retry a dead webhook delivery
+// @Description  Moves a dead webhook delivery back to pending with a fresh attempt budget. Requires the operator admin role.
+// @Tags         dashboard
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id   path      int  true  "Delivery ID"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /dashboard/webhooks/deliveries/{id}/retry [post]
+func (h *DashboardHandler) RetryWebhookDelivery(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	requeued, err := h.webhookService.RetryDelivery(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry webhook delivery"})
+		return
+	}
+
+	if !requeued {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No dead webhook delivery with this ID"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"success": true, "id": id})
+}
@@ -0,0 +1,127 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/service"
+)
+
+type DigestHandler struct {
+	service *service.DigestService
+}
+
+func NewDigestHandler(digestService *service.DigestService) *DigestHandler {
+	return &DigestHandler{service: digestService}
+}
+
+// SetDigestPreferences godoc
+// @Summary      Set email digest preferences
+// @Description  Creates or replaces a user's daily email digest settings. The digest lists the unread notifications received since the previous one and is sent once a day after send_hour (0-23, default 8) in timezone (IANA name, default UTC). Requires an API key.
+// @Tags         digests
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        request  body      models.SetDigestPreferenceRequest  true  "Digest preferences"
+// @Success      200     {object}  models.DigestPreference
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /digest-preferences [put]
+func (h *DigestHandler) SetDigestPreferences(c *gin.Context) {
+	var req models.SetDigestPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request. 'user_id' and a valid 'email' are required; 'send_hour' must be between 0 and 23.",
+		})
+		return
+	}
+
+	pref, err := h.service.SetPreferences(c.Request.Context(), req)
+	if errors.Is(err, service.ErrInvalidTimezone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timezone. Use an IANA name such as 'Europe/Berlin'."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save digest preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
+
+// GetDigestPreferences godoc
+// @Summary      Get email digest preferences
+// @Description  Returns a user's daily email digest settings. Requires an API key.
+// @Tags         digests
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        user_id  query     string  true  "User ID"
+// @Success      200      {object}  models.DigestPreference
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /digest-preferences [get]
+func (h *DigestHandler) GetDigestPreferences(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'user_id' query parameter is required"})
+		return
+	}
+
+	pref, err := h.service.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve digest preferences"})
+		return
+	}
+
+	if pref == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Digest preferences not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pref)
+}
+
+// DeleteDigestPreferences godoc
+// @Summary      Delete email digest preferences
+// @Description  Removes a user's digest settings, which stops their digest emails. Requires an API key.
+// @Tags         digests
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        user_id  query     string  true  "User ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /digest-preferences [delete]
+func (h *DigestHandler) DeleteDigestPreferences(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'user_id' query parameter is required"})
+		return
+	}
+
+	deleted, err := h.service.DeletePreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete digest preferences"})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Digest preferences not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,55 @@
+package transport
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/service"
+)
+
+type EventHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewEventHandler(notificationService *service.NotificationService) *EventHandler {
+	return &EventHandler{notificationService: notificationService}
+}
+
+// ChapterReleased godoc
+// @Summary      Ingest a chapter release
+// @Description  Records that a publication released a chapter and creates a notification for every user bookmarking it. Replaying the same event does not notify users twice. Requires the operator admin role or an API key with the notifications:publish scope.
+// @Tags         events
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        request  body      models.ChapterReleasedEvent  true  "Chapter release"
+// @Success      200     {object}  models.ChapterReleasedResponse
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /events/chapter-released [post]
+func (h *EventHandler) ChapterReleased(c *gin.Context) {
+	var event models.ChapterReleasedEvent
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request. 'publication_id' and 'chapter_id' fields are required.",
+		})
+		return
+	}
+
+	notifications, err := h.notificationService.PublishChapterReleased(c.Request.Context(), event)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ChapterReleasedResponse{
+		PublicationID: event.PublicationID,
+		ChapterID:     event.ChapterID,
+		Notified:      len(notifications),
+	})
+}
@@ -12,10 +12,12 @@ type HelloResponse struct {
 
 // Hello godoc
 // @Summary      Hello endpoint
-// @Description  Returns a greeting message. Requires API key as query parameter 'api'.
+// @Description  Returns a greeting message. Requires an API key.
 // @Tags         hello
 // @Produce      json
-// @Param        api   query     string  true  "API Key"
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
 // @Success      200   {object}  HelloResponse
 // @Failure      403   {object}  map[string]string
 // @Router       /hello [get]
@@ -0,0 +1,189 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/service"
+)
+
+type NotificationHandler struct {
+	service *service.NotificationService
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: notificationService}
+}
+
+// ListNotifications godoc
+// @Summary      List a user's notifications
+// @Description  Returns the user's inbox newest first. Pass the returned next_cursor as 'cursor' to fetch the next page. Requires an API key.
+// @Tags         notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        user_id      query     string  true   "User ID"
+// @Param        limit        query     int     false  "Limit (default 50, max 200)"
+// @Param        cursor       query     string  false  "Cursor from a previous page"
+// @Param        unread_only  query     bool    false  "Only return unread notifications"
+// @Success      200          {object}  models.NotificationPage
+// @Failure      400          {object}  map[string]string
+// @Failure      403          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Router       /notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	var params models.NotificationQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters. 'user_id' is required."})
+		return
+	}
+
+	page, err := h.service.ListInbox(c.Request.Context(), params)
+	if errors.Is(err, service.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// UnreadCount godoc
+// @Summary      Get unread notification count
+// @Description  Returns the number of unread notifications for a user. Requires an API key.
+// @Tags         notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        user_id  query     string  true  "User ID"
+// @Success      200      {object}  models.UnreadCountResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /notifications/unread-count [get]
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'user_id' query parameter is required"})
+		return
+	}
+
+	count, err := h.service.UnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count unread notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.UnreadCountResponse{UserID: userID, Unread: count})
+}
+
+// MarkRead godoc
+// @Summary      Mark notifications as read
+// @Description  Marks one or more of the user's notifications as read. Requires an API key.
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        request  body      models.MarkNotificationsReadRequest  true  "Notification IDs"
+// @Success      200     {object}  models.MarkNotificationsReadResponse
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /notifications/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	var req models.MarkNotificationsReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. 'user_id' and a non-empty 'ids' list are required."})
+		return
+	}
+
+	updated, err := h.service.MarkRead(c.Request.Context(), req.UserID, req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MarkNotificationsReadResponse{Updated: updated})
+}
+
+// MarkAllRead godoc
+// @Summary      Mark all notifications as read
+// @Description  Marks every unread notification of the user as read. Requires an API key.
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        request  body      models.MarkAllNotificationsReadRequest  true  "User"
+// @Success      200     {object}  models.MarkNotificationsReadResponse
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	var req models.MarkAllNotificationsReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. 'user_id' field is required."})
+		return
+	}
+
+	updated, err := h.service.MarkAllRead(c.Request.Context(), req.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MarkNotificationsReadResponse{Updated: updated})
+}
+
+// DeleteNotification godoc
+// @Summary      Delete a notification
+// @Description  Deletes one of the user's notifications. Requires an API key.
+// @Tags         notifications
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id       path      int     true  "Notification ID"
+// @Param        user_id  query     string  true  "User ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /notifications/{id} [delete]
+func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	id, ok := parseIDParam(c, "notification")
+	if !ok {
+		return
+	}
+
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'user_id' query parameter is required"})
+		return
+	}
+
+	deleted, err := h.service.DeleteNotification(c.Request.Context(), userID, int64(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification"})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,169 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/models"
+)
+
+const (
+	streamHeartbeatInterval = 15 * time.Second
+	streamReplayBatchSize   = 100
+	// streamReplayDedupeWindow is how long after a replay live copies of the
+	// replayed notifications are still expected from the hub.
+	streamReplayDedupeWindow = time.Minute
+)
+
+// StreamNotifications godoc
+// @Summary      Stream notifications (Server-Sent Events)
+// @Description  Pushes the user's new notifications as 'notification' events whose id is the notification ID, with a 'heartbeat' event every 15 seconds. Reconnecting clients send the Last-Event-ID header (or 'last_event_id' query parameter) to replay notifications they missed. Requires an API key; browsers' EventSource cannot set headers, so pass it as the 'api' query parameter or via the /auth cookie.
+// @Tags         notifications
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        user_id        query     string  true   "User ID"
+// @Param        last_event_id  query     int     false  "Resume after this notification ID"
+// @Param        Last-Event-ID  header    int     false  "Resume after this notification ID"
+// @Success      200
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /notifications/stream [get]
+func (h *NotificationHandler) StreamNotifications(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'user_id' query parameter is required"})
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	var lastID int64
+	if lastEventID != "" {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || id < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+			return
+		}
+		lastID = id
+	}
+
+	ctx := c.Request.Context()
+
+	// Subscribe before replaying so nothing created in between is lost;
+	// live copies of replayed notifications are skipped.
+	sub := h.service.Subscribe(userID)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	var replayed sentNotifications
+	if lastID > 0 {
+		for {
+			missed, err := h.service.ListSince(ctx, userID, lastID, streamReplayBatchSize)
+			if err != nil {
+				return
+			}
+			for _, n := range missed {
+				if writeNotificationEvent(c.Writer, n) != nil {
+					return
+				}
+				replayed.add(n.ID)
+				lastID = n.ID
+			}
+			if len(missed) < streamReplayBatchSize {
+				break
+			}
+		}
+	}
+	replayed.expireAfter(time.Now().Add(streamReplayDedupeWindow))
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n, ok := <-sub.C:
+			if !ok {
+				// Server shutting down or client too slow; it will reconnect
+				// with Last-Event-ID and replay from the inbox.
+				return
+			}
+			if replayed.seen(n.ID, time.Now()) {
+				continue
+			}
+			if writeNotificationEvent(c.Writer, n) != nil {
+				return
+			}
+		case t := <-heartbeat.C:
+			if sse.Encode(c.Writer, sse.Event{Event: "heartbeat", Data: t.Unix()}) != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeNotificationEvent(w io.Writer, n models.Notification) error {
+	return sse.Encode(w, sse.Event{
+		Id:    strconv.FormatInt(n.ID, 10),
+		Event: "notification",
+		Data:  n,
+	})
+}
+
+// sentNotifications remembers the ids replayed to a stream so the live
+// copies the hub delivers for them are skipped. Ids are assigned when a row
+// is inserted, not when it commits, so a live notification can carry a lower
+// id than one already sent; skipping everything up to the highest id sent
+// would lose it for good. Nothing is remembered after the expiry, by when
+// every live copy has arrived.
+type sentNotifications struct {
+	ids     map[int64]struct{}
+	expires time.Time
+}
+
+func (s *sentNotifications) add(id int64) {
+	if s.ids == nil {
+		s.ids = make(map[int64]struct{})
+	}
+	s.ids[id] = struct{}{}
+}
+
+// expireAfter sets when the replayed ids are forgotten.
+func (s *sentNotifications) expireAfter(t time.Time) {
+	s.expires = t
+}
+
+// seen reports whether id was replayed. Each id is reported once, since the
+// hub delivers a notification at most once.
+func (s *sentNotifications) seen(id int64, now time.Time) bool {
+	if len(s.ids) == 0 {
+		return false
+	}
+	if now.After(s.expires) {
+		s.ids = nil
+		return false
+	}
+	if _, ok := s.ids[id]; !ok {
+		return false
+	}
+	delete(s.ids, id)
+	return true
+}
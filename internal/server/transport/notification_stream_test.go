@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSentNotificationsSkipsOnlyReplayedIDs(t *testing.T) {
+	now := time.Now()
+
+	var replayed sentNotifications
+	for _, id := range []int64{11, 12, 14} {
+		replayed.add(id)
+	}
+	replayed.expireAfter(now.Add(time.Minute))
+
+	// 13 was inserted before 14 but committed after the replay read past it
+	for _, tt := range []struct {
+		id   int64
+		seen bool
+	}{
+		{12, true},
+		{13, false},
+		{14, true},
+		{14, false}, // each replayed id is skipped once
+		{15, false},
+	} {
+		if seen := replayed.seen(tt.id, now); seen != tt.seen {
+			t.Errorf("seen(%d) = %v, want %v", tt.id, seen, tt.seen)
+		}
+	}
+
+	// After the window nothing is skipped and the set is released
+	if replayed.seen(11, now.Add(2*time.Minute)) {
+		t.Error("seen(11) after the window = true, want false")
+	}
+	if replayed.ids != nil {
+		t.Errorf("ids after the window = %v, want nil", replayed.ids)
+	}
+
+	var empty sentNotifications
+	if empty.seen(1, now) {
+		t.Error("empty set reported an id as seen")
+	}
+}
@@ -0,0 +1,251 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"fandom/notifications/internal/middleware"
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/realtime"
+	"fandom/notifications/internal/service"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingInterval   = wsPongWait * 9 / 10
+	wsMaxMessageSize = 4096
+)
+
+type WebSocketHandler struct {
+	service  *service.NotificationService
+	upgrader websocket.Upgrader
+}
+
+func NewWebSocketHandler(notificationService *service.NotificationService, allowedOrigins []string) *WebSocketHandler {
+	return &WebSocketHandler{
+		service: notificationService,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     checkOrigin(allowedOrigins),
+		},
+	}
+}
+
+// Connect godoc
+// @Summary      Notification WebSocket
+// @Description  Upgrades to a WebSocket. Clients send {"type":"subscribe","user_id":"...","last_event_id":0} to receive {"type":"notification"} messages for that user (missed notifications after last_event_id are replayed first), and {"type":"ack","ids":[...]} or {"type":"ack_all"} to mark notifications as read (acks need the notifications:write scope). Requires an API key; browsers cannot set headers on WebSocket requests, so pass it as the 'api' query parameter or via the /auth cookie.
+// @Tags         notifications
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Success      101
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /notifications/ws [get]
+func (h *WebSocketHandler) Connect(c *gin.Context) {
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already written an HTTP error response
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	apiKey := middleware.CurrentAPIKey(c)
+	canAck := apiKey != nil && apiKey.HasScope(models.ScopeNotificationsWrite)
+
+	messages := make(chan models.WebSocketClientMessage)
+	go h.readLoop(ctx, conn, messages)
+
+	var sub *realtime.Subscription
+	// replayed holds what the last subscribe replayed, so the live copies
+	// are not sent twice
+	var replayed sentNotifications
+	defer func() {
+		if sub != nil {
+			sub.Close()
+		}
+	}()
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		// A nil channel blocks forever, so no notifications arrive until the
+		// client subscribes.
+		var notifications <-chan models.Notification
+		if sub != nil {
+			notifications = sub.C
+		}
+
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			switch msg.Type {
+			case "subscribe":
+				if msg.UserID == "" {
+					if writeWS(conn, models.WebSocketServerMessage{Type: "error", Error: "'user_id' is required to subscribe"}) != nil {
+						return
+					}
+					continue
+				}
+
+				if sub != nil {
+					sub.Close()
+				}
+				sub = h.service.Subscribe(msg.UserID)
+				lastID := msg.LastEventID
+				replayed = sentNotifications{}
+
+				if writeWS(conn, models.WebSocketServerMessage{Type: "subscribed", UserID: msg.UserID}) != nil {
+					return
+				}
+
+				if lastID > 0 {
+					for {
+						missed, err := h.service.ListSince(ctx, msg.UserID, lastID, streamReplayBatchSize)
+						if err != nil {
+							_ = writeWS(conn, models.WebSocketServerMessage{Type: "error", Error: "Failed to replay missed notifications"})
+							return
+						}
+						for i := range missed {
+							if writeWS(conn, models.WebSocketServerMessage{Type: "notification", Notification: &missed[i]}) != nil {
+								return
+							}
+							replayed.add(missed[i].ID)
+							lastID = missed[i].ID
+						}
+						if len(missed) < streamReplayBatchSize {
+							break
+						}
+					}
+				}
+				replayed.expireAfter(time.Now().Add(streamReplayDedupeWindow))
+
+			case "ack", "ack_all":
+				if !canAck {
+					if writeWS(conn, models.WebSocketServerMessage{Type: "error", Error: "API key is missing the 'notifications:write' scope"}) != nil {
+						return
+					}
+					continue
+				}
+				if sub == nil {
+					if writeWS(conn, models.WebSocketServerMessage{Type: "error", Error: "Subscribe before acknowledging notifications"}) != nil {
+						return
+					}
+					continue
+				}
+
+				var updated int64
+				var err error
+				if msg.Type == "ack_all" {
+					updated, err = h.service.MarkAllRead(ctx, sub.UserID)
+				} else if len(msg.IDs) > 0 {
+					updated, err = h.service.MarkRead(ctx, sub.UserID, msg.IDs)
+				}
+
+				reply := models.WebSocketServerMessage{Type: "ack", UserID: sub.UserID, Updated: &updated}
+				if err != nil {
+					reply = models.WebSocketServerMessage{Type: "error", Error: "Failed to mark notifications as read"}
+				}
+				if writeWS(conn, reply) != nil {
+					return
+				}
+
+			default:
+				if writeWS(conn, models.WebSocketServerMessage{Type: "error", Error: "Unknown message type"}) != nil {
+					return
+				}
+			}
+
+		case n, ok := <-notifications:
+			if !ok {
+				// Hub shut down or the client fell behind; it reconnects and
+				// resubscribes with last_event_id to catch up.
+				_ = conn.WriteControl(
+					websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, "subscription closed"),
+					time.Now().Add(wsWriteWait),
+				)
+				return
+			}
+			if replayed.seen(n.ID, time.Now()) {
+				continue
+			}
+			if writeWS(conn, models.WebSocketServerMessage{Type: "notification", Notification: &n}) != nil {
+				return
+			}
+
+		case <-ping.C:
+			if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)) != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLoop decodes client messages until the connection fails or ctx is done.
+// It closes messages on exit.
+func (h *WebSocketHandler) readLoop(ctx context.Context, conn *websocket.Conn, messages chan<- models.WebSocketClientMessage) {
+	defer close(messages)
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var msg models.WebSocketClientMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		select {
+		case messages <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func writeWS(conn *websocket.Conn, msg models.WebSocketServerMessage) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return conn.WriteJSON(msg)
+}
+
+// checkOrigin allows requests without an Origin header (non-browser clients),
+// same-origin requests and the configured extra origins.
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+
+		for _, allowed := range allowedOrigins {
+			if allowed == "*" || strings.EqualFold(allowed, origin) {
+				return true
+			}
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		return strings.EqualFold(u.Host, r.Host)
+	}
+}
@@ -0,0 +1,19 @@
+package transport
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseIDParam reads the positive integer ":id" path parameter and writes a 400
+// response when it is missing or malformed.
+func parseIDParam(c *gin.Context, resource string) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + resource + " ID"})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,143 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/service"
+)
+
+type PushHandler struct {
+	service *service.PushService
+}
+
+func NewPushHandler(pushService *service.PushService) *PushHandler {
+	return &PushHandler{service: pushService}
+}
+
+// GetVAPIDPublicKey godoc
+// @Summary      Get the VAPID public key
+// @Description  Returns the application server key to pass as applicationServerKey to pushManager.subscribe in the browser. Requires an API key.
+// @Tags         push
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Success      200  {object}  models.VAPIDPublicKeyResponse
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /push/vapid-public-key [get]
+func (h *PushHandler) GetVAPIDPublicKey(c *gin.Context) {
+	publicKey, err := h.service.PublicKey()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Web push is not configured"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.VAPIDPublicKeyResponse{PublicKey: publicKey})
+}
+
+// RegisterPushSubscription godoc
+// @Summary      Register a push subscription
+// @Description  Stores a browser PushSubscription (the output of subscription.toJSON()) for a user. Registering an endpoint again replaces its keys and owner. Requires an API key.
+// @Tags         push
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        request  body      models.RegisterPushSubscriptionRequest  true  "Push subscription"
+// @Success      201      {object}  models.PushSubscription
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /push-subscriptions [post]
+func (h *PushHandler) RegisterPushSubscription(c *gin.Context) {
+	var req models.RegisterPushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request. 'user_id', 'subscription.endpoint', 'subscription.keys.p256dh' and 'subscription.keys.auth' are required.",
+		})
+		return
+	}
+
+	sub, err := h.service.Subscribe(c.Request.Context(), req, c.Request.UserAgent())
+	if errors.Is(err, service.ErrInvalidPushSubscription) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register push subscription"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+// ListPushSubscriptions godoc
+// @Summary      List push subscriptions
+// @Description  Lists the browsers registered for push notifications for a user. Requires an API key.
+// @Tags         push
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        user_id  query     string  true  "User ID"
+// @Success      200      {array}   models.PushSubscription
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /push-subscriptions [get]
+func (h *PushHandler) ListPushSubscriptions(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'user_id' query parameter is required"})
+		return
+	}
+
+	subs, err := h.service.ListSubscriptions(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve push subscriptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, subs)
+}
+
+// UnregisterPushSubscription godoc
+// @Summary      Unregister a push subscription
+// @Description  Removes a push subscription by its endpoint URL, e.g. after subscription.unsubscribe() in the browser. Requires an API key.
+// @Tags         push
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        endpoint  query     string  true  "Subscription endpoint URL"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /push-subscriptions [delete]
+func (h *PushHandler) UnregisterPushSubscription(c *gin.Context) {
+	endpoint := c.Query("endpoint")
+	if endpoint == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'endpoint' query parameter is required"})
+		return
+	}
+
+	deleted, err := h.service.Unsubscribe(c.Request.Context(), endpoint)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unregister push subscription"})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Push subscription not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
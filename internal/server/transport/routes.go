@@ -1,30 +1,116 @@
 package transport
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	"fandom/notifications/internal/database"
+	"fandom/notifications/internal/middleware"
+	"fandom/notifications/internal/models"
 	"fandom/notifications/internal/service"
 )
 
-func RegisterAdminRoutes(rg *gin.RouterGroup, db *database.DB, apiKeyService *service.APIKeyService) {
-	// Admin routes (require master API key via middleware)
-	apiKeyHandler := NewAPIKeyHandler(apiKeyService)
-	rg.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+func RegisterAuthRoutes(rg *gin.RouterGroup, sessionService *service.SessionService, secureCookie bool) {
+	// Auth routes (public; exchange a key for a session cookie)
+	authHandler := NewAuthHandler(sessionService, secureCookie)
+	rg.GET("", authHandler.AuthPage)
+	rg.POST("/set", authHandler.SetAuthCookie)
+	rg.POST("/clear", authHandler.ClearAuthCookie)
+}
+
+func RegisterAdminRoutes(rg *gin.RouterGroup, db *database.DB, adminService *service.AdminService, apiKeyService *service.APIKeyService, rotationGrace time.Duration) {
+	// Admin routes (require an admin key via middleware; key management is owner-only)
+	viewer := middleware.RequireRole(models.AdminRoleViewer)
+	owner := middleware.RequireRole(models.AdminRoleOwner)
+
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService, rotationGrace)
+	rg.POST("/api-keys", owner, apiKeyHandler.CreateAPIKey)
+	rg.GET("/api-keys", viewer, apiKeyHandler.ListAPIKeys)
+	rg.PUT("/api-keys/:id", owner, apiKeyHandler.RenameAPIKey)
+	rg.PUT("/api-keys/:id/rate-limit", owner, apiKeyHandler.SetAPIKeyRateLimit)
+	rg.POST("/api-keys/:id/revoke", owner, apiKeyHandler.RevokeAPIKey)
+	rg.POST("/api-keys/:id/reactivate", owner, apiKeyHandler.ReactivateAPIKey)
+	rg.POST("/api-keys/:id/rotate", owner, apiKeyHandler.RotateAPIKey)
+	rg.DELETE("/api-keys/:id", owner, apiKeyHandler.DeleteAPIKey)
+
+	adminHandler := NewAdminHandler(adminService)
+	rg.POST("/admins", owner, adminHandler.CreateAdmin)
+	rg.GET("/admins", owner, adminHandler.ListAdmins)
+	rg.GET("/admins/me", viewer, adminHandler.CurrentAdmin)
+	rg.PUT("/admins/:id", owner, adminHandler.UpdateAdmin)
+	rg.DELETE("/admins/:id", owner, adminHandler.DeleteAdmin)
+}
+
+func RegisterDashboardRoutes(rg *gin.RouterGroup, logService *service.LogService, webhookService *service.WebhookService) {
+	// Dashboard routes (admins; the log APIs also accept API keys with logs:read)
+	viewer := middleware.RequireRole(models.AdminRoleViewer)
+	operator := middleware.RequireRole(models.AdminRoleOperator)
+	logsRead := middleware.RequireRoleOrScope(models.AdminRoleViewer, models.ScopeLogsRead)
+
+	dashboardHandler := NewDashboardHandler(logService, webhookService)
+	rg.GET("", viewer, dashboardHandler.DashboardPage)
+	rg.GET("/logs", logsRead, dashboardHandler.GetLogs)
+	rg.GET("/stats", logsRead, dashboardHandler.GetStats)
+	rg.GET("/logs/export", logsRead, dashboardHandler.ExportLogs)
+	rg.GET("/logs/partitions", viewer, dashboardHandler.GetLogPartitions)
+	rg.GET("/webhooks/deliveries", viewer, dashboardHandler.GetWebhookDeliveries)
+	rg.GET("/webhooks/deliveries/:id", viewer, dashboardHandler.GetWebhookDelivery)
+	rg.POST("/webhooks/deliveries/:id/retry", operator, dashboardHandler.RetryWebhookDelivery)
 }
 
-func RegisterDashboardRoutes(rg *gin.RouterGroup, logService *service.LogService) {
-	// Dashboard routes (require master API key via middleware)
-	dashboardHandler := NewDashboardHandler(logService)
-	rg.GET("", dashboardHandler.DashboardPage)
-	rg.GET("/logs", dashboardHandler.GetLogs)
-	rg.GET("/stats", dashboardHandler.GetStats)
+func RegisterEventRoutes(rg *gin.RouterGroup, notificationService *service.NotificationService) {
+	// Event ingestion routes (operators and owners, or an API key with notifications:publish)
+	eventHandler := NewEventHandler(notificationService)
+	rg.POST("/chapter-released", middleware.RequireRoleOrScope(models.AdminRoleOperator, models.ScopeNotificationsPublish), eventHandler.ChapterReleased)
 }
 
-func RegisterRoutes(rg *gin.RouterGroup, db *database.DB) {
+func RegisterRoutes(rg *gin.RouterGroup, db *database.DB, bookmarkService *service.BookmarkService, notificationService *service.NotificationService, webhookService *service.WebhookService, digestService *service.DigestService, pushService *service.PushService, wsAllowedOrigins []string) {
 	// Protected routes (require regular API key via middleware)
 	rg.GET("/hello", hello)
-	// TODO: Add bookmark routes here
-}
 
+	bookmarksRead := middleware.RequireScope(models.ScopeBookmarksRead)
+	bookmarksWrite := middleware.RequireScope(models.ScopeBookmarksWrite)
+	notificationsRead := middleware.RequireScope(models.ScopeNotificationsRead)
+	notificationsWrite := middleware.RequireScope(models.ScopeNotificationsWrite)
+	webhooksRead := middleware.RequireScope(models.ScopeWebhooksRead)
+	webhooksWrite := middleware.RequireScope(models.ScopeWebhooksWrite)
+	preferencesRead := middleware.RequireScope(models.ScopePreferencesRead)
+	preferencesWrite := middleware.RequireScope(models.ScopePreferencesWrite)
+
+	bookmarkHandler := NewBookmarkHandler(bookmarkService)
+	rg.POST("/bookmarks", bookmarksWrite, bookmarkHandler.CreateBookmark)
+	rg.GET("/bookmarks", bookmarksRead, bookmarkHandler.ListBookmarks)
+	rg.PUT("/bookmarks/position", bookmarksWrite, bookmarkHandler.SetReadingPosition)
+	rg.GET("/bookmarks/:id", bookmarksRead, bookmarkHandler.GetBookmark)
+	rg.PUT("/bookmarks/:id", bookmarksWrite, bookmarkHandler.UpdateBookmark)
+	rg.DELETE("/bookmarks/:id", bookmarksWrite, bookmarkHandler.DeleteBookmark)
+
+	notificationHandler := NewNotificationHandler(notificationService)
+	rg.GET("/notifications", notificationsRead, notificationHandler.ListNotifications)
+	rg.GET("/notifications/unread-count", notificationsRead, notificationHandler.UnreadCount)
+	rg.GET("/notifications/stream", notificationsRead, notificationHandler.StreamNotifications)
+	rg.POST("/notifications/read", notificationsWrite, notificationHandler.MarkRead)
+	rg.POST("/notifications/read-all", notificationsWrite, notificationHandler.MarkAllRead)
+	rg.DELETE("/notifications/:id", notificationsWrite, notificationHandler.DeleteNotification)
+
+	// Acks over the socket additionally need notifications:write
+	webSocketHandler := NewWebSocketHandler(notificationService, wsAllowedOrigins)
+	rg.GET("/notifications/ws", notificationsRead, webSocketHandler.Connect)
 
+	webhookHandler := NewWebhookHandler(webhookService)
+	rg.POST("/webhooks", webhooksWrite, webhookHandler.CreateWebhook)
+	rg.GET("/webhooks", webhooksRead, webhookHandler.ListWebhooks)
+	rg.DELETE("/webhooks/:id", webhooksWrite, webhookHandler.DeleteWebhook)
+
+	digestHandler := NewDigestHandler(digestService)
+	rg.PUT("/digest-preferences", preferencesWrite, digestHandler.SetDigestPreferences)
+	rg.GET("/digest-preferences", preferencesRead, digestHandler.GetDigestPreferences)
+	rg.DELETE("/digest-preferences", preferencesWrite, digestHandler.DeleteDigestPreferences)
+
+	pushHandler := NewPushHandler(pushService)
+	rg.GET("/push/vapid-public-key", preferencesRead, pushHandler.GetVAPIDPublicKey)
+	rg.POST("/push-subscriptions", preferencesWrite, pushHandler.RegisterPushSubscription)
+	rg.GET("/push-subscriptions", preferencesRead, pushHandler.ListPushSubscriptions)
+	rg.DELETE("/push-subscriptions", preferencesWrite, pushHandler.UnregisterPushSubscription)
+}
@@ -0,0 +1,119 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"fandom/notifications/internal/middleware"
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/service"
+)
+
+type WebhookHandler struct {
+	service *service.WebhookService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: webhookService}
+}
+
+// CreateWebhook godoc
+// @Summary      Subscribe a webhook
+// @Description  Registers a URL that receives signed JSON POSTs for the given event types (chapter.released, notification.created). The URL must not point to a loopback, private, link-local or other internal address. A signing secret is generated when omitted and is only returned in this response. Requires an API key.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        request  body      models.CreateWebhookRequest  true  "Webhook subscription"
+// @Success      201     {object}  models.WebhookSubscription
+// @Failure      400     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request. 'url' and a non-empty 'event_types' list are required.",
+		})
+		return
+	}
+
+	apiKey := middleware.CurrentAPIKey(c)
+
+	sub, err := h.service.CreateSubscription(c.Request.Context(), apiKey.ID, req)
+	if errors.Is(err, service.ErrInvalidWebhookURL) || errors.Is(err, service.ErrWebhookURLNotAllowed) || errors.Is(err, service.ErrUnknownWebhookEventType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+// ListWebhooks godoc
+// @Summary      List webhooks
+// @Description  Lists the webhook subscriptions of the calling API key. Secrets are not returned. Requires an API key.
+// @Tags         webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Success      200  {array}   models.WebhookSubscription
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	apiKey := middleware.CurrentAPIKey(c)
+
+	subs, err := h.service.ListSubscriptions(c.Request.Context(), apiKey.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, subs)
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete a webhook
+// @Description  Deletes one of the calling API key's webhook subscriptions along with its pending deliveries. Requires an API key.
+// @Tags         webhooks
+// @Security     BearerAuth
+// @Security     APIKeyHeader
+// @Security     APIKeyQuery
+// @Param        id   path      int     true  "Webhook ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c, "webhook")
+	if !ok {
+		return
+	}
+
+	apiKey := middleware.CurrentAPIKey(c)
+
+	deleted, err := h.service.DeleteSubscription(c.Request.Context(), apiKey.ID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
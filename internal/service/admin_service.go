@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+)
+
+// bootstrapAdminName is the owner created from MASTER_API_KEY.
+const bootstrapAdminName = "Owner (MASTER_API_KEY)"
+
+// adminStore is the part of AdminRepository the service uses.
+type adminStore interface {
+	Create(ctx context.Context, name, keyHash, prefix, role string) (*models.Admin, error)
+	Bootstrap(ctx context.Context, name, keyHash, prefix string) (bool, error)
+	FindByHash(ctx context.Context, keyHash string) (*models.Admin, error)
+	FindByID(ctx context.Context, id int) (*models.Admin, error)
+	UpdateLastUsedBatch(ctx context.Context, usedAt map[int]time.Time) error
+	List(ctx context.Context) ([]models.Admin, error)
+	Update(ctx context.Context, id int, role *string, active *bool) (*models.Admin, error)
+	Delete(ctx context.Context, id int) (bool, error)
+}
+
+// AdminService manages the admin accounts that use the admin API and the
+// dashboard. Admin keys are generated and hashed like API keys, and
+// Authenticate caches and records them the same way APIKeyService.ValidateKey
+// does. Start and Stop run the last_used_at batch writer.
+type AdminService struct {
+	repo adminStore
+	// cache is nil when caching is disabled.
+	cache    *keyCache[models.Admin]
+	lastUsed *lastUsedRecorder
+}
+
+// NewAdminService returns a service that caches authenticated admins for
+// cacheTTL. A cacheTTL of 0 disables the cache.
+func NewAdminService(repo *repository.AdminRepository, cacheTTL time.Duration) *AdminService {
+	s := &AdminService{repo: repo}
+	s.lastUsed = newLastUsedRecorder("admins", func(ctx context.Context, usedAt map[int]time.Time) error {
+		return s.repo.UpdateLastUsedBatch(ctx, usedAt)
+	})
+	if cacheTTL > 0 {
+		s.cache = newKeyCache[models.Admin](cacheTTL)
+	}
+	return s
+}
+
+// Start writes pending last_used_at times every lastUsedFlushInterval until
+// Stop is called.
+func (s *AdminService) Start() {
+	s.lastUsed.Start()
+}
+
+// Stop writes the remaining last_used_at times, or gives up when ctx expires.
+func (s *AdminService) Stop(ctx context.Context) error {
+	return s.lastUsed.Stop(ctx)
+}
+
+// Bootstrap makes masterKey the key of an owner account when no admins exist
+// yet, so deployments that used MASTER_API_KEY keep working. Once any admin
+// exists the variable is ignored. It reports whether an owner was created.
+func (s *AdminService) Bootstrap(ctx context.Context, masterKey string) (bool, error) {
+	if masterKey == "" {
+		return false, nil
+	}
+	return s.repo.Bootstrap(ctx, bootstrapAdminName, HashAPIKey(masterKey), keyPrefix(masterKey))
+}
+
+// Authenticate returns the active admin whose key is key, or nil if there is
+// none. Lookups, including misses, are served from the cache while fresh;
+// changes made through this service clear it.
+func (s *AdminService) Authenticate(ctx context.Context, key string) (*models.Admin, error) {
+	hash := HashAPIKey(key)
+	now := time.Now()
+
+	var admin *models.Admin
+	var generation uint64
+	cached := false
+	if s.cache != nil {
+		admin, generation, cached = s.cache.get(hash, now)
+	}
+
+	if !cached {
+		var err error
+		admin, err = s.repo.FindByHash(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.cache != nil {
+			s.cache.put(hash, admin, now, generation)
+		}
+	}
+
+	if admin == nil {
+		return nil, nil
+	}
+
+	s.lastUsed.mark(admin.ID, now)
+
+	return admin, nil
+}
+
+// invalidate forgets cached lookups after an admin changes.
+func (s *AdminService) invalidate() {
+	if s.cache != nil {
+		s.cache.clear()
+	}
+}
+
+// FindActiveAdmin returns admin id if it exists and is active, or nil.
+func (s *AdminService) FindActiveAdmin(ctx context.Context, id int) (*models.Admin, error) {
+	admin, err := s.repo.FindByID(ctx, id)
+	if err != nil || admin == nil || !admin.Active {
+		return nil, err
+	}
+	return admin, nil
+}
+
+// CreateAdmin issues a new admin key. The key is only returned here.
+func (s *AdminService) CreateAdmin(ctx context.Context, name, role string) (*models.CreateAdminResponse, error) {
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := s.repo.Create(ctx, name, HashAPIKey(key), keyPrefix(key), role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin: %w", err)
+	}
+
+	return &models.CreateAdminResponse{
+		ID:        admin.ID,
+		Name:      admin.Name,
+		Role:      admin.Role,
+		Key:       key,
+		Prefix:    admin.Prefix,
+		CreatedAt: admin.CreatedAt,
+	}, nil
+}
+
+func (s *AdminService) ListAdmins(ctx context.Context) ([]models.Admin, error) {
+	return s.repo.List(ctx)
+}
+
+// UpdateAdmin changes an admin's role or disables it. It returns nil if the
+// admin does not exist and repository.ErrLastOwner if no active owner would
+// remain.
+func (s *AdminService) UpdateAdmin(ctx context.Context, id int, role *string, active *bool) (*models.Admin, error) {
+	defer s.invalidate()
+	return s.repo.Update(ctx, id, role, active)
+}
+
+// DeleteAdmin removes an admin. It returns repository.ErrLastOwner instead of
+// deleting the last active owner.
+func (s *AdminService) DeleteAdmin(ctx context.Context, id int) (bool, error) {
+	defer s.invalidate()
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fandom/notifications/internal/models"
+)
+
+// countingAdminStore serves admins from memory and counts lookups. Methods
+// the tests don't use fall through to the nil adminStore and panic.
+type countingAdminStore struct {
+	adminStore
+
+	admins     map[string]*models.Admin
+	findByHash atomic.Int64
+	updates    []map[int]time.Time
+}
+
+func (s *countingAdminStore) FindByHash(ctx context.Context, keyHash string) (*models.Admin, error) {
+	s.findByHash.Add(1)
+	admin, ok := s.admins[keyHash]
+	if !ok || !admin.Active {
+		return nil, nil
+	}
+	copied := *admin
+	return &copied, nil
+}
+
+func (s *countingAdminStore) UpdateLastUsedBatch(ctx context.Context, usedAt map[int]time.Time) error {
+	s.updates = append(s.updates, usedAt)
+	return nil
+}
+
+func (s *countingAdminStore) Update(ctx context.Context, id int, role *string, active *bool) (*models.Admin, error) {
+	for _, admin := range s.admins {
+		if admin.ID == id {
+			if active != nil {
+				admin.Active = *active
+			}
+			copied := *admin
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestAuthenticateUsesCacheAndBatchesLastUsed(t *testing.T) {
+	store := &countingAdminStore{admins: map[string]*models.Admin{
+		HashAPIKey("owner-key"): {ID: 1, Name: "Owner", Role: models.AdminRoleOwner, Active: true},
+	}}
+	s := NewAdminService(nil, time.Minute)
+	s.repo = store
+	ctx := context.Background()
+
+	for range 5 {
+		if admin, err := s.Authenticate(ctx, "owner-key"); err != nil || admin == nil || admin.ID != 1 {
+			t.Fatalf("Authenticate(owner) = %v, %v", admin, err)
+		}
+		if admin, err := s.Authenticate(ctx, "api-key"); err != nil || admin != nil {
+			t.Fatalf("Authenticate(unknown) = %v, %v", admin, err)
+		}
+	}
+	if got := store.findByHash.Load(); got != 2 {
+		t.Errorf("FindByHash called %d times, want 2 (one per key)", got)
+	}
+
+	// Authenticating writes nothing until the batch is flushed
+	if len(store.updates) != 0 {
+		t.Fatalf("last_used_at written before flush: %v", store.updates)
+	}
+	s.lastUsed.flush()
+	if len(store.updates) != 1 || len(store.updates[0]) != 1 {
+		t.Fatalf("flushed batches = %v, want one batch for admin 1", store.updates)
+	}
+
+	// Disabling an admin through the service takes effect immediately
+	inactive := false
+	if _, err := s.UpdateAdmin(ctx, 1, nil, &inactive); err != nil {
+		t.Fatal(err)
+	}
+	if admin, err := s.Authenticate(ctx, "owner-key"); err != nil || admin != nil {
+		t.Errorf("Authenticate(disabled) = %v, %v; want nil", admin, err)
+	}
+}
@@ -3,22 +3,88 @@ package service
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"fandom/notifications/internal/models"
 	"fandom/notifications/internal/repository"
 )
 
+// apiKeyPrefixLength is how many leading characters of a key are stored in
+// the clear so operators can tell keys apart. It matches the masking in the
+// request logs.
+const apiKeyPrefixLength = 8
+
+var (
+	// ErrUnknownScope is returned when a key is requested with a scope that
+	// does not exist.
+	ErrUnknownScope = errors.New("unknown API key scope")
+	// ErrInvalidExpiry is returned when a new key's expiry is not in the future.
+	ErrInvalidExpiry = errors.New("expires_at must be in the future")
+	// ErrAPIKeyExpired is returned by ValidateKey for keys past their expiry.
+	ErrAPIKeyExpired = errors.New("api key has expired")
+)
+
+// apiKeyStore is the part of APIKeyRepository the service uses.
+type apiKeyStore interface {
+	Create(ctx context.Context, keyHash, prefix, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	FindByID(ctx context.Context, id int) (*models.APIKey, error)
+	UpdateLastUsedBatch(ctx context.Context, usedAt map[int]time.Time) error
+	List(ctx context.Context) ([]models.APIKey, error)
+	SetActive(ctx context.Context, id int, active bool) (*models.APIKey, error)
+	Rename(ctx context.Context, id int, name string) (*models.APIKey, error)
+	SetRateLimit(ctx context.Context, id int, perMinute, burst *int) (*models.APIKey, error)
+	Rotate(ctx context.Context, id int, keyHash, prefix string, grace time.Duration) (successor, previous *models.APIKey, err error)
+	Delete(ctx context.Context, id int) (bool, error)
+}
+
+// APIKeyService manages API keys. ValidateKey results are cached for
+// cacheTTL and last_used_at is written in periodic batches, so a protected
+// request normally costs no database round-trip. Start and Stop run the
+// batch writer.
 type APIKeyService struct {
-	repo *repository.APIKeyRepository
+	repo apiKeyStore
+	// cache is nil when caching is disabled.
+	cache    *keyCache[models.APIKey]
+	lastUsed *lastUsedRecorder
 }
 
-func NewAPIKeyService(repo *repository.APIKeyRepository) *APIKeyService {
-	return &APIKeyService{repo: repo}
+// NewAPIKeyService returns a service that caches validated keys for cacheTTL.
+// A cacheTTL of 0 disables the cache.
+func NewAPIKeyService(repo *repository.APIKeyRepository, cacheTTL time.Duration) *APIKeyService {
+	s := &APIKeyService{repo: repo}
+	s.lastUsed = newLastUsedRecorder("api keys", func(ctx context.Context, usedAt map[int]time.Time) error {
+		return s.repo.UpdateLastUsedBatch(ctx, usedAt)
+	})
+	if cacheTTL > 0 {
+		s.cache = newKeyCache[models.APIKey](cacheTTL)
+	}
+	return s
+}
+
+// Start writes pending last_used_at times every lastUsedFlushInterval until
+// Stop is called.
+func (s *APIKeyService) Start() {
+	s.lastUsed.Start()
+}
+
+// Stop writes the remaining last_used_at times, or gives up when ctx expires.
+func (s *APIKeyService) Stop(ctx context.Context) error {
+	return s.lastUsed.Stop(ctx)
 }
 
 func (s *APIKeyService) GenerateKey() (string, error) {
+	return generateKey()
+}
+
+// generateKey returns a random 256-bit key, hex encoded. Admin keys use the
+// same format.
+func generateKey() (string, error) {
 	bytes := make([]byte, 32) // 256 bits
 	if _, err := rand.Read(bytes); err != nil {
 		return "", fmt.Errorf("failed to generate random bytes: %w", err)
@@ -26,37 +92,192 @@ func (s *APIKeyService) GenerateKey() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-func (s *APIKeyService) CreateAPIKey(ctx context.Context, name string) (*models.CreateAPIKeyResponse, error) {
+// keyPrefix returns the leading characters of key stored for identification.
+func keyPrefix(key string) string {
+	return key[:min(len(key), apiKeyPrefixLength)]
+}
+
+// CreateAPIKey issues a key with the given scopes, or DefaultAPIKeyScopes
+// when none are given.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, name string, scopes []string, expiresAt *time.Time) (*models.CreateAPIKeyResponse, error) {
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
 	key, err := s.GenerateKey()
 	if err != nil {
 		return nil, err
 	}
 
-	apiKey, err := s.repo.Create(ctx, key, name)
+	apiKey, err := s.repo.Create(ctx, HashAPIKey(key), keyPrefix(key), name, scopes, expiresAt)
 	if err != nil {
 		return nil, err
 	}
 
+	// The plaintext key is only returned here; it cannot be recovered later
 	return &models.CreateAPIKeyResponse{
-		Key:       apiKey.Key,
+		Key:       key,
+		Prefix:    apiKey.Prefix,
 		Name:      apiKey.Name,
+		Scopes:    apiKey.Scopes,
+		ExpiresAt: apiKey.ExpiresAt,
 		CreatedAt: apiKey.CreatedAt,
 	}, nil
 }
 
-func (s *APIKeyService) ValidateKey(ctx context.Context, key string) (bool, error) {
-	apiKey, err := s.repo.FindByKey(ctx, key)
-	if err != nil {
-		return false, err
+// ValidateKey returns the active API key matching key, or nil if there is
+// none. Keys past their expiry return ErrAPIKeyExpired. Lookups, including
+// misses, are served from the cache while fresh; changes made through this
+// service clear it, changes made elsewhere show up after the cache TTL.
+func (s *APIKeyService) ValidateKey(ctx context.Context, key string) (*models.APIKey, error) {
+	hash := HashAPIKey(key)
+	now := time.Now()
+
+	var apiKey *models.APIKey
+	var generation uint64
+	cached := false
+	if s.cache != nil {
+		apiKey, generation, cached = s.cache.get(hash, now)
+	}
+
+	if !cached {
+		var err error
+		apiKey, err = s.repo.FindByHash(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.cache != nil {
+			s.cache.put(hash, apiKey, now, generation)
+		}
 	}
 
 	if apiKey == nil {
-		return false, nil
+		return nil, nil
+	}
+
+	if apiKey.Expired(now) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	s.lastUsed.mark(apiKey.ID, now)
+
+	return apiKey, nil
+}
+
+// invalidate forgets cached lookups after a key changes.
+func (s *APIKeyService) invalidate() {
+	if s.cache != nil {
+		s.cache.clear()
+	}
+}
+
+// FindUsableKey returns key id if it is active and not expired, or nil.
+func (s *APIKeyService) FindUsableKey(ctx context.Context, id int) (*models.APIKey, error) {
+	apiKey, err := s.repo.FindByID(ctx, id)
+	if err != nil || apiKey == nil || !apiKey.Active || apiKey.Expired(time.Now()) {
+		return nil, err
 	}
+	return apiKey, nil
+}
 
-	// Update last used timestamp
-	_ = s.repo.UpdateLastUsed(ctx, key)
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+// RevokeAPIKey deactivates a key so it is rejected from the next request on.
+// It returns nil if the key does not exist.
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id int) (*models.APIKey, error) {
+	defer s.invalidate()
+	return s.repo.SetActive(ctx, id, false)
+}
+
+// ReactivateAPIKey undoes RevokeAPIKey.
+func (s *APIKeyService) ReactivateAPIKey(ctx context.Context, id int) (*models.APIKey, error) {
+	defer s.invalidate()
+	return s.repo.SetActive(ctx, id, true)
+}
 
-	return true, nil
+func (s *APIKeyService) RenameAPIKey(ctx context.Context, id int, name string) (*models.APIKey, error) {
+	defer s.invalidate()
+	return s.repo.Rename(ctx, id, name)
 }
 
+// SetAPIKeyRateLimit overrides a key's rate limit; nil values fall back to
+// the server defaults. It returns nil if the key does not exist.
+func (s *APIKeyService) SetAPIKeyRateLimit(ctx context.Context, id int, perMinute, burst *int) (*models.APIKey, error) {
+	defer s.invalidate()
+	return s.repo.SetRateLimit(ctx, id, perMinute, burst)
+}
+
+// RotateAPIKey issues a successor for key id with the same name and scopes.
+// The old key keeps working for grace so clients can switch over, and its
+// webhook subscriptions move to the successor. It returns nil if the key does
+// not exist.
+func (s *APIKeyService) RotateAPIKey(ctx context.Context, id int, grace time.Duration) (*models.RotateAPIKeyResponse, error) {
+	key, err := s.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	successor, previous, err := s.repo.Rotate(ctx, id, HashAPIKey(key), keyPrefix(key), grace)
+	s.invalidate()
+	if err != nil {
+		return nil, err
+	}
+
+	if successor == nil {
+		return nil, nil
+	}
+
+	return &models.RotateAPIKeyResponse{
+		CreateAPIKeyResponse: models.CreateAPIKeyResponse{
+			Key:       key,
+			Prefix:    successor.Prefix,
+			Name:      successor.Name,
+			Scopes:    successor.Scopes,
+			ExpiresAt: successor.ExpiresAt,
+			CreatedAt: successor.CreatedAt,
+		},
+		ID:                   successor.ID,
+		PreviousKeyID:        previous.ID,
+		PreviousKeyExpiresAt: *previous.ExpiresAt,
+	}, nil
+}
+
+// DeleteAPIKey removes a key along with its webhook subscriptions.
+func (s *APIKeyService) DeleteAPIKey(ctx context.Context, id int) (bool, error) {
+	defer s.invalidate()
+	return s.repo.Delete(ctx, id)
+}
+
+// normalizeScopes validates scopes and removes duplicates.
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return slices.Clone(models.DefaultAPIKeyScopes), nil
+	}
+
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(models.APIKeyScopes, scope) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownScope, scope)
+		}
+		if !slices.Contains(normalized, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
+}
+
+// HashAPIKey returns the hex SHA-256 digest stored in api_keys.key_hash.
+// Keys are 256 random bits, so a fast unsalted hash is enough: there is
+// nothing to brute-force, and lookups stay a single indexed equality match.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fandom/notifications/internal/models"
+)
+
+// countingAPIKeyStore serves keys from memory and counts the lookups and
+// writes that would be database round-trips. Methods the tests don't use
+// fall through to the nil apiKeyStore and panic.
+type countingAPIKeyStore struct {
+	apiKeyStore
+
+	keys map[string]*models.APIKey
+	// latency is added to every FindByHash to stand in for a round-trip
+	latency time.Duration
+
+	findByHash atomic.Int64
+
+	mu          sync.Mutex
+	failUpdates int
+	updates     []map[int]time.Time
+}
+
+func newCountingAPIKeyStore(keys ...string) *countingAPIKeyStore {
+	store := &countingAPIKeyStore{keys: make(map[string]*models.APIKey)}
+	for i, key := range keys {
+		store.keys[HashAPIKey(key)] = &models.APIKey{ID: i + 1, Name: key, Active: true}
+	}
+	return store
+}
+
+func (s *countingAPIKeyStore) FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	s.findByHash.Add(1)
+	time.Sleep(s.latency)
+	key, ok := s.keys[keyHash]
+	if !ok {
+		return nil, nil
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (s *countingAPIKeyStore) UpdateLastUsedBatch(ctx context.Context, usedAt map[int]time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failUpdates > 0 {
+		s.failUpdates--
+		return errors.New("connection refused")
+	}
+	s.updates = append(s.updates, usedAt)
+	return nil
+}
+
+func newTestAPIKeyService(store *countingAPIKeyStore, cacheTTL time.Duration) *APIKeyService {
+	s := NewAPIKeyService(nil, cacheTTL)
+	s.repo = store
+	return s
+}
+
+// benchmarkDBRoundTrip is a typical round-trip to a Postgres server on the
+// same network.
+const benchmarkDBRoundTrip = 200 * time.Microsecond
+
+// BenchmarkValidateKey compares ValidateKey with and without the cache. The
+// db-lookups/op metric is what the cache saves: one round-trip per request
+// without it, none once the key is cached. Lookups are slowed down by
+// benchmarkDBRoundTrip so ns/op reflects the difference too.
+func BenchmarkValidateKey(b *testing.B) {
+	for _, bm := range []struct {
+		name     string
+		cacheTTL time.Duration
+	}{
+		{"uncached", 0},
+		{"cached", time.Minute},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			store := newCountingAPIKeyStore("benchmark-key")
+			store.latency = benchmarkDBRoundTrip
+			s := newTestAPIKeyService(store, bm.cacheTTL)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for b.Loop() {
+				if key, err := s.ValidateKey(ctx, "benchmark-key"); err != nil || key == nil {
+					b.Fatalf("ValidateKey() = %v, %v", key, err)
+				}
+			}
+			b.ReportMetric(float64(store.findByHash.Load())/float64(b.N), "db-lookups/op")
+		})
+	}
+}
+
+func TestValidateKeyUsesCache(t *testing.T) {
+	store := newCountingAPIKeyStore("valid-key")
+	s := newTestAPIKeyService(store, time.Minute)
+	ctx := context.Background()
+
+	for range 5 {
+		if key, err := s.ValidateKey(ctx, "valid-key"); err != nil || key == nil || key.ID != 1 {
+			t.Fatalf("ValidateKey(valid) = %v, %v", key, err)
+		}
+		if key, err := s.ValidateKey(ctx, "unknown-key"); err != nil || key != nil {
+			t.Fatalf("ValidateKey(unknown) = %v, %v", key, err)
+		}
+	}
+	if got := store.findByHash.Load(); got != 2 {
+		t.Errorf("FindByHash called %d times, want 2 (one per key)", got)
+	}
+
+	// Changes made through the service drop cached lookups
+	s.invalidate()
+	if _, err := s.ValidateKey(ctx, "valid-key"); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.findByHash.Load(); got != 3 {
+		t.Errorf("FindByHash called %d times after invalidate, want 3", got)
+	}
+}
+
+func TestFlushLastUsedRequeuesOnFailure(t *testing.T) {
+	store := newCountingAPIKeyStore()
+	store.failUpdates = 1
+	s := newTestAPIKeyService(store, 0)
+
+	t1 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	s.lastUsed.mark(1, t1)
+	s.lastUsed.mark(2, t1)
+
+	s.lastUsed.flush()
+	if len(store.updates) != 0 {
+		t.Fatalf("failed flush recorded updates: %v", store.updates)
+	}
+
+	// Key 2 is used again before the retry; its newer time must win
+	t2 := t1.Add(time.Minute)
+	s.lastUsed.mark(2, t2)
+
+	s.lastUsed.flush()
+	if len(store.updates) != 1 {
+		t.Fatalf("got %d batches, want 1", len(store.updates))
+	}
+	batch := store.updates[0]
+	if len(batch) != 2 || !batch[1].Equal(t1) || !batch[2].Equal(t2) {
+		t.Errorf("retried batch = %v, want key 1 at %v and key 2 at %v", batch, t1, t2)
+	}
+
+	// Nothing is left pending once the batch is written
+	s.lastUsed.flush()
+	if len(store.updates) != 1 {
+		t.Errorf("empty flush wrote another batch: %v", store.updates[1:])
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+)
+
+type BookmarkService struct {
+	repo *repository.BookmarkRepository
+}
+
+func NewBookmarkService(repo *repository.BookmarkRepository) *BookmarkService {
+	return &BookmarkService{repo: repo}
+}
+
+func (s *BookmarkService) CreateBookmark(ctx context.Context, req models.CreateBookmarkRequest) (*models.Bookmark, error) {
+	return s.repo.Create(ctx, req)
+}
+
+func (s *BookmarkService) GetBookmark(ctx context.Context, id int) (*models.Bookmark, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *BookmarkService) ListBookmarks(ctx context.Context, params models.BookmarkQueryParams) ([]models.Bookmark, int64, error) {
+	return s.repo.List(ctx, params)
+}
+
+func (s *BookmarkService) UpdateBookmark(ctx context.Context, id int, req models.UpdateBookmarkRequest) (*models.Bookmark, error) {
+	return s.repo.Update(ctx, id, req)
+}
+
+func (s *BookmarkService) DeleteBookmark(ctx context.Context, id int) (bool, error) {
+	return s.repo.Delete(ctx, id)
+}
+
+// SetReadingPosition upserts the user's bookmark for a publication and reports
+// whether the position was created or moved forward or backward.
+func (s *BookmarkService) SetReadingPosition(ctx context.Context, req models.UpsertBookmarkRequest) (*models.UpsertBookmarkResponse, error) {
+	bookmark, previous, err := s.repo.Upsert(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UpsertBookmarkResponse{
+		Bookmark: bookmark,
+		Movement: compareReadingPosition(previous, bookmark),
+	}, nil
+}
+
+func compareReadingPosition(previous, current *models.Bookmark) models.BookmarkMovement {
+	if previous == nil {
+		return models.BookmarkCreated
+	}
+
+	if previous.ChapterID == current.ChapterID &&
+		previous.Chapter == current.Chapter &&
+		previous.Volume == current.Volume {
+		return models.BookmarkUnchanged
+	}
+
+	prevVolume, ok1 := parsePositionNumber(previous.Volume)
+	curVolume, ok2 := parsePositionNumber(current.Volume)
+	prevChapter, ok3 := parsePositionNumber(previous.Chapter)
+	curChapter, ok4 := parsePositionNumber(current.Chapter)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return models.BookmarkChanged
+	}
+
+	switch {
+	case curVolume > prevVolume:
+		return models.BookmarkForward
+	case curVolume < prevVolume:
+		return models.BookmarkBackward
+	case curChapter > prevChapter:
+		return models.BookmarkForward
+	case curChapter < prevChapter:
+		return models.BookmarkBackward
+	default:
+		return models.BookmarkChanged
+	}
+}
+
+// parsePositionNumber parses chapter/volume labels such as "12", "12.5" or
+// "Vol. 3". An empty label counts as zero.
+func parsePositionNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, true
+	}
+
+	start := strings.IndexFunc(s, func(r rune) bool { return r >= '0' && r <= '9' })
+	if start < 0 {
+		return 0, false
+	}
+	end := start
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.') {
+		end++
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimRight(s[start:end], "."), 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
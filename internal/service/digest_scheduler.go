@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"sync"
+	"text/template"
+	"time"
+
+	"fandom/notifications/internal/mailer"
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+)
+
+const (
+	digestPollInterval = time.Minute
+	digestBatchSize    = 100
+	digestSendTimeout  = 30 * time.Second
+	// digestMaxItems caps how many notifications are listed in one email;
+	// the rest are summarized as a count.
+	digestMaxItems = 50
+)
+
+// DigestScheduler emails each user with digest preferences one summary per
+// day of the unread notifications they received since the previous digest.
+type DigestScheduler struct {
+	digests       *repository.DigestRepository
+	notifications *repository.NotificationRepository
+	sender        mailer.Sender
+	from          string
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewDigestScheduler(digests *repository.DigestRepository, notifications *repository.NotificationRepository, sender mailer.Sender, from string) *DigestScheduler {
+	return &DigestScheduler{
+		digests:       digests,
+		notifications: notifications,
+		sender:        sender,
+		from:          from,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start checks for due digests every minute until Stop is called.
+func (s *DigestScheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(digestPollInterval)
+		defer ticker.Stop()
+
+		for {
+			s.sendDue()
+
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the digests in flight to be sent, or for ctx to expire.
+func (s *DigestScheduler) Stop(ctx context.Context) error {
+	close(s.stop)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *DigestScheduler) sendDue() {
+	for {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), digestSendTimeout)
+		prefs, err := s.digests.ClaimDue(ctx, digestBatchSize)
+		cancel()
+		if err != nil {
+			log.Printf("digests: failed to claim due digests: %v", err)
+			return
+		}
+
+		for _, pref := range prefs {
+			if err := s.send(pref); err != nil {
+				log.Printf("digests: failed to send digest to user %s: %v", pref.UserID, err)
+
+				ctx, cancel := context.WithTimeout(context.Background(), digestSendTimeout)
+				if err := s.digests.RestoreLastSent(ctx, pref.UserID, pref.LastSentAt); err != nil {
+					log.Printf("digests: failed to reschedule digest for user %s: %v", pref.UserID, err)
+				}
+				cancel()
+			}
+		}
+
+		if len(prefs) < digestBatchSize {
+			return
+		}
+	}
+}
+
+// send emails the digest for one claimed preference. pref.LastSentAt is the
+// previous send time; users with nothing new since then get no email.
+func (s *DigestScheduler) send(pref models.DigestPreference) error {
+	ctx, cancel := context.WithTimeout(context.Background(), digestSendTimeout)
+	defer cancel()
+
+	since := time.Now().Add(-24 * time.Hour)
+	if pref.LastSentAt != nil && pref.LastSentAt.After(since) {
+		since = *pref.LastSentAt
+	}
+
+	notifications, total, err := s.notifications.ListUnreadSince(ctx, pref.UserID, since, digestMaxItems)
+	if err != nil {
+		return err
+	}
+	if total == 0 {
+		return nil
+	}
+
+	msg, err := BuildDigestMessage(s.from, pref, notifications, total)
+	if err != nil {
+		return err
+	}
+
+	return s.sender.Send(ctx, msg)
+}
+
+type digestTemplateData struct {
+	Notifications []models.Notification
+	Total         int64
+	More          int64
+}
+
+var digestTextTemplate = template.Must(template.New("digest.txt").Parse(`Here's what's new for your bookmarks:
+{{range .Notifications}}
+- {{if .Name}}{{.Name}}{{else}}{{.PublicationID}}{{end}}: {{if .Volume}}Vol. {{.Volume}} {{end}}Chapter {{if .Chapter}}{{.Chapter}}{{else}}{{.ChapterID}}{{end}}
+{{- end}}
+{{if .More}}
+...and {{.More}} more.
+{{end}}
+You're receiving this daily digest because you enabled it. Update your digest preferences to change the time or turn it off.
+`))
+
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; color: #333;">
+    <h2>Here's what's new for your bookmarks</h2>
+    <ul>
+    {{range .Notifications}}
+        <li><strong>{{if .Name}}{{.Name}}{{else}}{{.PublicationID}}{{end}}</strong>: {{if .Volume}}Vol. {{.Volume}} {{end}}Chapter {{if .Chapter}}{{.Chapter}}{{else}}{{.ChapterID}}{{end}}</li>
+    {{end}}
+    </ul>
+    {{if .More}}<p>...and {{.More}} more.</p>{{end}}
+    <p style="color: #999; font-size: 12px;">You're receiving this daily digest because you enabled it. Update your digest preferences to change the time or turn it off.</p>
+</body>
+</html>
+`))
+
+// BuildDigestMessage renders the digest email for a user. total is the number
+// of unread notifications, of which notifications is the listed subset.
+func BuildDigestMessage(from string, pref models.DigestPreference, notifications []models.Notification, total int64) (mailer.Message, error) {
+	data := digestTemplateData{
+		Notifications: notifications,
+		Total:         total,
+		More:          total - int64(len(notifications)),
+	}
+
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.Execute(&text, data); err != nil {
+		return mailer.Message{}, err
+	}
+	if err := digestHTMLTemplate.Execute(&html, data); err != nil {
+		return mailer.Message{}, err
+	}
+
+	subject := "1 new chapter for your bookmarks"
+	if total != 1 {
+		subject = fmt.Sprintf("%d new chapters for your bookmarks", total)
+	}
+
+	return mailer.Message{
+		From:     from,
+		To:       []string{pref.Email},
+		Subject:  subject,
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+	}, nil
+}
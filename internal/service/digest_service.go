@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+)
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+const defaultDigestSendHour = 8
+
+type DigestService struct {
+	repo *repository.DigestRepository
+}
+
+func NewDigestService(repo *repository.DigestRepository) *DigestService {
+	return &DigestService{repo: repo}
+}
+
+// SetPreferences creates or replaces a user's digest preferences. Omitted
+// fields fall back to enabled, 08:00 and UTC.
+func (s *DigestService) SetPreferences(ctx context.Context, req models.SetDigestPreferenceRequest) (*models.DigestPreference, error) {
+	pref := models.DigestPreference{
+		UserID:   req.UserID,
+		Email:    req.Email,
+		Enabled:  true,
+		SendHour: defaultDigestSendHour,
+		Timezone: req.Timezone,
+	}
+
+	if req.Enabled != nil {
+		pref.Enabled = *req.Enabled
+	}
+	if req.SendHour != nil {
+		pref.SendHour = *req.SendHour
+	}
+	if pref.Timezone == "" {
+		pref.Timezone = "UTC"
+	}
+
+	// "Local" means the server's zone, which Postgres doesn't know
+	if _, err := time.LoadLocation(pref.Timezone); err != nil || pref.Timezone == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+
+	return s.repo.Upsert(ctx, pref)
+}
+
+func (s *DigestService) GetPreferences(ctx context.Context, userID string) (*models.DigestPreference, error) {
+	return s.repo.FindByUserID(ctx, userID)
+}
+
+func (s *DigestService) DeletePreferences(ctx context.Context, userID string) (bool, error) {
+	return s.repo.Delete(ctx, userID)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+)
+
+const (
+	jobPollInterval      = time.Second
+	jobDefaultWorkers    = 2
+	jobDefaultVisibility = 5 * time.Minute
+	jobDefaultAttempts   = 5
+	jobBaseBackoff       = 10 * time.Second
+	jobMaxBackoff        = time.Hour
+	// jobStoreTimeout bounds the queue's own claim and bookkeeping queries.
+	jobStoreTimeout = 10 * time.Second
+)
+
+// ErrPermanentJobFailure marks handler errors that retrying cannot fix. Wrap
+// it to move the job straight to the dead state.
+var ErrPermanentJobFailure = errors.New("permanent job failure")
+
+// JobHandler processes one job. Returning an error schedules a retry.
+type JobHandler func(ctx context.Context, job models.Job) error
+
+// JobQueueOptions configures how a queue's jobs are processed. Zero values
+// fall back to the defaults.
+type JobQueueOptions struct {
+	// Workers is how many jobs of this queue run concurrently.
+	Workers int
+	// Visibility is how long a claimed job is hidden from other workers. It
+	// is also the handler's timeout, so a slow job is never run twice at once.
+	Visibility time.Duration
+	// MaxAttempts is how many times a job is tried before it is marked dead.
+	MaxAttempts int
+}
+
+// jobStore is the part of JobRepository the queue uses.
+type jobStore interface {
+	Enqueue(ctx context.Context, queue string, payloads []string, delay time.Duration, maxAttempts int) error
+	Claim(ctx context.Context, queue string, limit int, visibility time.Duration) ([]models.Job, error)
+	Complete(ctx context.Context, id int64, attempt int) error
+	Fail(ctx context.Context, id int64, attempt int, lastError string, retryIn time.Duration, dead bool) error
+}
+
+type jobQueue struct {
+	handler JobHandler
+	opts    JobQueueOptions
+	wake    chan struct{}
+}
+
+// JobQueue is a durable background job queue backed by the jobs table.
+// Workers claim jobs with SELECT ... FOR UPDATE SKIP LOCKED, so any number of
+// server instances can share the table.
+//
+// Webhook deliveries and digests predate it and keep their own tables:
+// deliveries record every attempt and can be requeued by admins, and digests
+// are claimed by each user's send time rather than enqueued.
+type JobQueue struct {
+	repo   jobStore
+	queues map[string]*jobQueue
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewJobQueue(repo *repository.JobRepository) *JobQueue {
+	return &JobQueue{
+		repo:   repo,
+		queues: make(map[string]*jobQueue),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Register sets the handler for a queue. It must be called before Start.
+func (q *JobQueue) Register(queue string, handler JobHandler, opts JobQueueOptions) {
+	if opts.Workers <= 0 {
+		opts.Workers = jobDefaultWorkers
+	}
+	if opts.Visibility <= 0 {
+		opts.Visibility = jobDefaultVisibility
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = jobDefaultAttempts
+	}
+
+	q.queues[queue] = &jobQueue{
+		handler: handler,
+		opts:    opts,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Enqueue adds one job per payload to queue, due immediately. Payloads are
+// encoded as JSON.
+func (q *JobQueue) Enqueue(ctx context.Context, queue string, payloads ...any) error {
+	return q.EnqueueIn(ctx, queue, 0, payloads...)
+}
+
+// EnqueueAt adds jobs that become due at runAt.
+func (q *JobQueue) EnqueueAt(ctx context.Context, queue string, runAt time.Time, payloads ...any) error {
+	return q.EnqueueIn(ctx, queue, max(time.Until(runAt), 0), payloads...)
+}
+
+// EnqueueIn adds jobs that become due after delay.
+func (q *JobQueue) EnqueueIn(ctx context.Context, queue string, delay time.Duration, payloads ...any) error {
+	if len(payloads) == 0 {
+		return nil
+	}
+
+	encoded := make([]string, len(payloads))
+	for i, payload := range payloads {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("encode %s job: %w", queue, err)
+		}
+		encoded[i] = string(data)
+	}
+
+	maxAttempts := jobDefaultAttempts
+	if registered, ok := q.queues[queue]; ok {
+		maxAttempts = registered.opts.MaxAttempts
+	}
+
+	if err := q.repo.Enqueue(ctx, queue, encoded, delay, maxAttempts); err != nil {
+		return err
+	}
+
+	// Let an idle local worker pick the job up without waiting for the poll
+	if registered, ok := q.queues[queue]; ok && delay <= 0 {
+		select {
+		case registered.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Start launches the worker pools of every registered queue.
+func (q *JobQueue) Start() {
+	for name, queue := range q.queues {
+		for i := 0; i < queue.opts.Workers; i++ {
+			q.wg.Add(1)
+			go func() {
+				defer q.wg.Done()
+				q.work(name, queue)
+			}()
+		}
+	}
+}
+
+// Stop stops claiming new jobs and waits for running ones to finish, or for
+// ctx to expire. Jobs cut off by the deadline are retried by whichever
+// instance claims them after their visibility timeout.
+func (q *JobQueue) Stop(ctx context.Context) error {
+	close(q.stop)
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *JobQueue) work(name string, queue *jobQueue) {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep claiming while there is work, then wait for the next poll
+		for q.runNext(name, queue) {
+			select {
+			case <-q.stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-q.stop:
+			return
+		case <-queue.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and runs one job, reporting whether there was one.
+func (q *JobQueue) runNext(name string, queue *jobQueue) bool {
+	claimCtx, cancel := context.WithTimeout(context.Background(), jobStoreTimeout)
+	jobs, err := q.repo.Claim(claimCtx, name, 1, queue.opts.Visibility)
+	cancel()
+	if err != nil {
+		log.Printf("jobs: failed to claim %s job: %v", name, err)
+		return false
+	}
+	if len(jobs) == 0 {
+		return false
+	}
+
+	job := jobs[0]
+
+	// The previous attempt's worker vanished without recording a result
+	if job.Attempts > job.MaxAttempts {
+		q.fail(job, errors.New("visibility timeout expired on final attempt"), true)
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queue.opts.Visibility)
+	err = runJobHandler(ctx, queue.handler, job)
+	cancel()
+
+	if err != nil {
+		dead := job.Attempts >= job.MaxAttempts || errors.Is(err, ErrPermanentJobFailure)
+		q.fail(job, err, dead)
+		return true
+	}
+
+	storeCtx, cancel := context.WithTimeout(context.Background(), jobStoreTimeout)
+	defer cancel()
+
+	if err := q.repo.Complete(storeCtx, job.ID, job.Attempts); err != nil {
+		log.Printf("jobs: failed to complete %s job %d: %v", name, job.ID, err)
+	}
+	return true
+}
+
+func (q *JobQueue) fail(job models.Job, jobErr error, dead bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobStoreTimeout)
+	defer cancel()
+
+	if dead {
+		log.Printf("jobs: %s job %d failed permanently after %d attempts: %v", job.Queue, job.ID, job.Attempts, jobErr)
+	}
+
+	if err := q.repo.Fail(ctx, job.ID, job.Attempts, jobErr.Error(), jobBackoff(job.Attempts), dead); err != nil {
+		log.Printf("jobs: failed to record failure of %s job %d: %v", job.Queue, job.ID, err)
+	}
+}
+
+// runJobHandler turns a handler panic into an ordinary failed attempt.
+func runJobHandler(ctx context.Context, handler JobHandler, job models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// jobBackoff doubles the delay after every failed attempt, capped at
+// jobMaxBackoff, with up to 10% jitter.
+func jobBackoff(attempt int) time.Duration {
+	backoff := jobMaxBackoff
+	if attempt < 20 {
+		backoff = min(jobBaseBackoff<<(attempt-1), jobMaxBackoff)
+	}
+	return backoff + rand.N(backoff/10+1)
+}
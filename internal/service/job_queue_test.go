@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fandom/notifications/internal/models"
+)
+
+// fakeJobStore hands out the jobs it holds once and records what the queue
+// reports back.
+type fakeJobStore struct {
+	mu        sync.Mutex
+	due       []models.Job
+	claimErr  error
+	completed []int64
+	failed    []failedJob
+}
+
+type failedJob struct {
+	id        int64
+	attempt   int
+	lastError string
+	retryIn   time.Duration
+	dead      bool
+}
+
+func (s *fakeJobStore) Enqueue(ctx context.Context, queue string, payloads []string, delay time.Duration, maxAttempts int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, payload := range payloads {
+		s.due = append(s.due, models.Job{
+			ID:          int64(len(s.due) + 1),
+			Queue:       queue,
+			Payload:     []byte(payload),
+			MaxAttempts: maxAttempts,
+		})
+	}
+	return nil
+}
+
+func (s *fakeJobStore) Claim(ctx context.Context, queue string, limit int, visibility time.Duration) ([]models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.claimErr != nil {
+		return nil, s.claimErr
+	}
+	if len(s.due) == 0 {
+		return nil, nil
+	}
+	job := s.due[0]
+	s.due = s.due[1:]
+	return []models.Job{job}, nil
+}
+
+func (s *fakeJobStore) Complete(ctx context.Context, id int64, attempt int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed = append(s.completed, id)
+	return nil
+}
+
+func (s *fakeJobStore) Fail(ctx context.Context, id int64, attempt int, lastError string, retryIn time.Duration, dead bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = append(s.failed, failedJob{id: id, attempt: attempt, lastError: lastError, retryIn: retryIn, dead: dead})
+	return nil
+}
+
+const testJobQueue = "test"
+
+func newTestJobQueue(store *fakeJobStore, handler JobHandler) *JobQueue {
+	q := NewJobQueue(nil)
+	q.repo = store
+	q.Register(testJobQueue, handler, JobQueueOptions{Workers: 1, Visibility: time.Minute, MaxAttempts: 3})
+	return q
+}
+
+func TestJobBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, jobBaseBackoff},
+		{2, 2 * jobBaseBackoff},
+		{3, 4 * jobBaseBackoff},
+		{9, 256 * jobBaseBackoff},
+		{10, jobMaxBackoff},
+		{19, jobMaxBackoff},
+		{20, jobMaxBackoff},
+		{1000, jobMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.attempt), func(t *testing.T) {
+			for range 20 {
+				got := jobBackoff(tt.attempt)
+				if got < tt.want || got > tt.want+tt.want/10 {
+					t.Fatalf("jobBackoff(%d) = %v, want %v plus at most 10%%", tt.attempt, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestJobQueueRunNext(t *testing.T) {
+	errTransient := errors.New("connection reset")
+
+	tests := []struct {
+		name        string
+		attempts    int
+		handlerErr  error
+		panics      bool
+		wantRun     bool
+		wantFailed  bool
+		wantDead    bool
+		wantErrText string
+	}{
+		{name: "succeeds", attempts: 1, wantRun: true},
+		{name: "fails with attempts left", attempts: 2, handlerErr: errTransient, wantRun: true, wantFailed: true, wantErrText: "connection reset"},
+		{name: "fails on last attempt", attempts: 3, handlerErr: errTransient, wantRun: true, wantFailed: true, wantDead: true, wantErrText: "connection reset"},
+		{name: "fails permanently", attempts: 1, handlerErr: fmt.Errorf("%w: bad payload", ErrPermanentJobFailure), wantRun: true, wantFailed: true, wantDead: true, wantErrText: "bad payload"},
+		{name: "panics", attempts: 1, panics: true, wantRun: true, wantFailed: true, wantErrText: "panic: boom"},
+		{name: "panics on last attempt", attempts: 3, panics: true, wantRun: true, wantFailed: true, wantDead: true, wantErrText: "panic: boom"},
+		{name: "reclaimed after final attempt timed out", attempts: 4, wantFailed: true, wantDead: true, wantErrText: "visibility timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeJobStore{due: []models.Job{{ID: 7, Queue: testJobQueue, Attempts: tt.attempts, MaxAttempts: 3}}}
+			var ran bool
+			q := newTestJobQueue(store, func(ctx context.Context, job models.Job) error {
+				ran = true
+				if tt.panics {
+					panic("boom")
+				}
+				return tt.handlerErr
+			})
+
+			if !q.runNext(testJobQueue, q.queues[testJobQueue]) {
+				t.Fatal("runNext() = false, want true")
+			}
+			if ran != tt.wantRun {
+				t.Errorf("handler ran = %v, want %v", ran, tt.wantRun)
+			}
+
+			if !tt.wantFailed {
+				if len(store.completed) != 1 || store.completed[0] != 7 {
+					t.Errorf("completed = %v, want [7]", store.completed)
+				}
+				if len(store.failed) != 0 {
+					t.Errorf("failed = %+v, want none", store.failed)
+				}
+				return
+			}
+
+			if len(store.completed) != 0 {
+				t.Errorf("completed = %v, want none", store.completed)
+			}
+			if len(store.failed) != 1 {
+				t.Fatalf("failed = %+v, want one", store.failed)
+			}
+			failed := store.failed[0]
+			if failed.id != 7 || failed.attempt != tt.attempts {
+				t.Errorf("failed job %d attempt %d, want job 7 attempt %d", failed.id, failed.attempt, tt.attempts)
+			}
+			if failed.dead != tt.wantDead {
+				t.Errorf("dead = %v, want %v", failed.dead, tt.wantDead)
+			}
+			if !strings.Contains(failed.lastError, tt.wantErrText) {
+				t.Errorf("last error = %q, want it to contain %q", failed.lastError, tt.wantErrText)
+			}
+			if failed.retryIn < jobBaseBackoff {
+				t.Errorf("retry in %v, want at least %v", failed.retryIn, jobBaseBackoff)
+			}
+		})
+	}
+}
+
+func TestJobQueueRunNextWithoutJobs(t *testing.T) {
+	for _, store := range []*fakeJobStore{{}, {claimErr: errors.New("connection refused")}} {
+		q := newTestJobQueue(store, func(ctx context.Context, job models.Job) error {
+			t.Error("handler called without a job")
+			return nil
+		})
+
+		if q.runNext(testJobQueue, q.queues[testJobQueue]) {
+			t.Errorf("runNext() with claim error %v = true, want false", store.claimErr)
+		}
+	}
+}
+
+func TestJobQueueEnqueueUsesQueueMaxAttempts(t *testing.T) {
+	store := &fakeJobStore{}
+	q := newTestJobQueue(store, func(ctx context.Context, job models.Job) error { return nil })
+
+	if err := q.Enqueue(context.Background(), testJobQueue, map[string]int{"n": 1}, map[string]int{"n": 2}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	if len(store.due) != 2 {
+		t.Fatalf("enqueued %d jobs, want 2", len(store.due))
+	}
+	for _, job := range store.due {
+		if job.MaxAttempts != 3 {
+			t.Errorf("job %d max attempts = %d, want 3", job.ID, job.MaxAttempts)
+		}
+	}
+	if string(store.due[1].Payload) != `{"n":2}` {
+		t.Errorf("payload = %s, want {\"n\":2}", store.due[1].Payload)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// keyNegativeCacheTTL bounds how long an unknown key is remembered, so
+	// a key that starts working elsewhere (e.g. reactivated on another
+	// instance) is picked up quickly.
+	keyNegativeCacheTTL = 5 * time.Second
+	// keyCacheMaxEntries caps memory use when clients send many distinct
+	// invalid keys.
+	keyCacheMaxEntries = 10000
+
+	// lastUsedFlushInterval is how often the last_used_at times of
+	// authenticated keys are written back in one batch.
+	lastUsedFlushInterval = 15 * time.Second
+	lastUsedFlushTimeout  = 10 * time.Second
+)
+
+type keyCacheEntry[T any] struct {
+	// value is nil for hashes that matched nothing.
+	value   *T
+	expires time.Time
+}
+
+// keyCache remembers key lookups by key hash for a short TTL. API keys and
+// admin keys each have one.
+type keyCache[T any] struct {
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]keyCacheEntry[T]
+	// generation changes on every clear, so a lookup that raced with a key
+	// change does not cache what it read before the change.
+	generation uint64
+}
+
+func newKeyCache[T any](ttl time.Duration) *keyCache[T] {
+	return &keyCache[T]{
+		ttl:         ttl,
+		negativeTTL: min(ttl, keyNegativeCacheTTL),
+		entries:     make(map[string]keyCacheEntry[T]),
+	}
+}
+
+// get returns the cached lookup for hash. ok is false on a miss; a hit with a
+// nil value is a cached "not found". On a miss, pass generation to put.
+func (c *keyCache[T]) get(hash string, now time.Time) (value *T, generation uint64, ok bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[hash]
+	generation = c.generation
+	c.mu.RUnlock()
+
+	if !ok || now.After(entry.expires) {
+		return nil, generation, false
+	}
+	if entry.value == nil {
+		return nil, generation, true
+	}
+
+	// Callers get their own copy so the cached value cannot be modified
+	copied := *entry.value
+	return &copied, generation, true
+}
+
+// put caches a lookup that started at generation. It is dropped if the cache
+// has been cleared since.
+func (c *keyCache[T]) put(hash string, value *T, now time.Time, generation uint64) {
+	ttl := c.ttl
+	if value == nil {
+		ttl = c.negativeTTL
+	} else {
+		copied := *value
+		value = &copied
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if len(c.entries) >= keyCacheMaxEntries {
+		for h, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, h)
+			}
+		}
+		if len(c.entries) >= keyCacheMaxEntries {
+			clear(c.entries)
+		}
+	}
+
+	c.entries[hash] = keyCacheEntry[T]{value: value, expires: now.Add(ttl)}
+}
+
+// clear drops every entry. Changes to keys are rare enough that this beats
+// tracking which hash belongs to which id.
+func (c *keyCache[T]) clear() {
+	c.mu.Lock()
+	clear(c.entries)
+	c.generation++
+	c.mu.Unlock()
+}
+
+// lastUsedRecorder collects when keys were last used and writes the times in
+// periodic batches, so authenticating a request costs no write. Start and
+// Stop run the batch writer.
+type lastUsedRecorder struct {
+	// name identifies the keys in log messages.
+	name  string
+	write func(ctx context.Context, usedAt map[int]time.Time) error
+
+	mu      sync.Mutex
+	pending map[int]time.Time
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newLastUsedRecorder(name string, write func(ctx context.Context, usedAt map[int]time.Time) error) *lastUsedRecorder {
+	return &lastUsedRecorder{
+		name:    name,
+		write:   write,
+		pending: make(map[int]time.Time),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start writes pending times every lastUsedFlushInterval until Stop is
+// called.
+func (r *lastUsedRecorder) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(lastUsedFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				r.flush()
+				return
+			case <-ticker.C:
+				r.flush()
+			}
+		}
+	}()
+}
+
+// Stop writes the remaining times, or gives up when ctx expires.
+func (r *lastUsedRecorder) Stop(ctx context.Context) error {
+	close(r.stop)
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// mark records that key id was used; the time is written by the next flush.
+func (r *lastUsedRecorder) mark(id int, usedAt time.Time) {
+	r.mu.Lock()
+	r.pending[id] = usedAt
+	r.mu.Unlock()
+}
+
+func (r *lastUsedRecorder) flush() {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[int]time.Time, len(pending))
+	r.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lastUsedFlushTimeout)
+	defer cancel()
+
+	if err := r.write(ctx, pending); err != nil {
+		log.Printf("%s: failed to record last used times of %d keys: %v", r.name, len(pending), err)
+
+		// Keep them for the next flush unless the key was used again since
+		r.mu.Lock()
+		for id, usedAt := range pending {
+			if _, ok := r.pending[id]; !ok {
+				r.pending[id] = usedAt
+			}
+		}
+		r.mu.Unlock()
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"fandom/notifications/internal/models"
+)
+
+func TestKeyCacheNegativeTTL(t *testing.T) {
+	cache := newKeyCache[models.APIKey](time.Minute)
+	if cache.negativeTTL != keyNegativeCacheTTL {
+		t.Fatalf("negativeTTL = %v, want %v", cache.negativeTTL, keyNegativeCacheTTL)
+	}
+
+	now := time.Now()
+	cache.put("unknown", nil, now, 0)
+	cache.put("known", &models.APIKey{ID: 1}, now, 0)
+
+	// Misses are remembered, but only for the shorter negative TTL
+	if key, _, ok := cache.get("unknown", now.Add(keyNegativeCacheTTL-time.Millisecond)); !ok || key != nil {
+		t.Errorf("unknown key before negative TTL: key %v, hit %v; want cached miss", key, ok)
+	}
+	if _, _, ok := cache.get("unknown", now.Add(keyNegativeCacheTTL+time.Millisecond)); ok {
+		t.Error("unknown key still cached after the negative TTL")
+	}
+	if key, _, ok := cache.get("known", now.Add(keyNegativeCacheTTL+time.Millisecond)); !ok || key == nil {
+		t.Error("known key expired with the negative TTL")
+	}
+	if _, _, ok := cache.get("known", now.Add(time.Minute+time.Millisecond)); ok {
+		t.Error("known key still cached after the TTL")
+	}
+
+	// The negative TTL never outlives a shorter positive one
+	if short := newKeyCache[models.APIKey](time.Second); short.negativeTTL != time.Second {
+		t.Errorf("negativeTTL with a 1s TTL = %v, want 1s", short.negativeTTL)
+	}
+}
+
+func TestKeyCachePutAfterClear(t *testing.T) {
+	cache := newKeyCache[models.APIKey](time.Minute)
+	now := time.Now()
+
+	// A lookup misses, the key is revoked while it reads the database, and
+	// the lookup then tries to cache the active key it read
+	_, generation, ok := cache.get("hash", now)
+	if ok {
+		t.Fatal("empty cache returned a hit")
+	}
+	cache.clear()
+	cache.put("hash", &models.APIKey{ID: 1, Active: true}, now, generation)
+
+	if key, _, ok := cache.get("hash", now); ok {
+		t.Errorf("stale lookup was cached after clear: %v", key)
+	}
+
+	// Lookups that start after the clear are cached as usual
+	_, generation, _ = cache.get("hash", now)
+	cache.put("hash", &models.APIKey{ID: 1}, now, generation)
+	if _, _, ok := cache.get("hash", now); !ok {
+		t.Error("lookup started after clear was not cached")
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+)
+
+const (
+	logPartitionCheckInterval = time.Hour
+	logPartitionTimeout       = 5 * time.Minute
+	// logPartitionsAhead is how many future periods get a partition, so
+	// inserts keep landing in one while no instance is running.
+	logPartitionsAhead = 3
+)
+
+// Request log partition intervals.
+const (
+	LogPartitionDaily   = "day"
+	LogPartitionMonthly = "month"
+)
+
+// LogPartitionManager keeps request_logs partitions ahead of time and drops
+// the ones past the retention period. Every instance runs it; creating and
+// dropping partitions is idempotent, so they don't need to coordinate.
+type LogPartitionManager struct {
+	repo      *repository.LogRepository
+	interval  string
+	retention time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewLogPartitionManager creates a partition per interval ("day" or
+// "month") and drops partitions whose rows are all older than retention.
+// A retention of 0 keeps logs forever.
+func NewLogPartitionManager(repo *repository.LogRepository, interval string, retention time.Duration) *LogPartitionManager {
+	if interval != LogPartitionDaily && interval != LogPartitionMonthly {
+		log.Printf("Unknown REQUEST_LOG_PARTITION_INTERVAL %q, using %s", interval, LogPartitionDaily)
+		interval = LogPartitionDaily
+	}
+	if retention < 0 {
+		retention = 0
+	}
+
+	return &LogPartitionManager{
+		repo:      repo,
+		interval:  interval,
+		retention: retention,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start maintains the partitions right away and then hourly until Stop is
+// called.
+func (m *LogPartitionManager) Start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(logPartitionCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			m.maintain()
+
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for maintenance in progress to finish, or for ctx to expire.
+func (m *LogPartitionManager) Stop(ctx context.Context) error {
+	close(m.stop)
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Partitions lists the partitions with their sizes and the policy applied
+// to them.
+func (m *LogPartitionManager) Partitions(ctx context.Context) (*models.LogPartitionsResponse, error) {
+	partitions, err := m.repo.ListPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.LogPartitionsResponse{
+		Interval:         m.interval,
+		RetentionSeconds: int64(m.retention.Seconds()),
+		Partitions:       partitions,
+	}
+	for _, partition := range partitions {
+		response.TotalSizeBytes += partition.SizeBytes
+	}
+	return response, nil
+}
+
+func (m *LogPartitionManager) maintain() {
+	ctx, cancel := context.WithTimeout(context.Background(), logPartitionTimeout)
+	defer cancel()
+
+	if err := m.createAhead(ctx); err != nil {
+		log.Printf("request logs: failed to create partitions: %v", err)
+	}
+	if m.retention > 0 {
+		// Partition bounds come from the database clock, so the cutoff does too
+		now, err := m.repo.Now(ctx)
+		if err != nil {
+			log.Printf("request logs: failed to apply retention: %v", err)
+			return
+		}
+		if err := m.dropExpired(ctx, now.Add(-m.retention)); err != nil {
+			log.Printf("request logs: failed to apply retention: %v", err)
+		}
+	}
+}
+
+// createAhead makes sure partitions exist for the current period and the
+// logPartitionsAhead after it. New partitions continue from the last
+// existing one, so changing the interval never creates overlapping ranges.
+// It stops at the first failure: a skipped period would be left behind for
+// good, since the next run continues after the last partition.
+func (m *LogPartitionManager) createAhead(ctx context.Context) error {
+	partitions, err := m.repo.ListPartitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var after *time.Time
+	for _, partition := range partitions {
+		if partition.To != nil && (after == nil || partition.To.After(*after)) {
+			after = partition.To
+		}
+	}
+
+	periods, err := m.repo.PartitionPeriods(ctx, m.interval, after, logPartitionsAhead)
+	if err != nil {
+		return err
+	}
+	for _, period := range periods {
+		if err := m.repo.CreatePartition(ctx, period.From, period.To); err != nil {
+			return fmt.Errorf("partition from %s: %w", period.From.Format(time.DateTime), err)
+		}
+	}
+	return nil
+}
+
+// dropExpired drops partitions that end before cutoff and deletes older
+// rows from the default partition.
+func (m *LogPartitionManager) dropExpired(ctx context.Context, cutoff time.Time) error {
+	partitions, err := m.repo.ListPartitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, partition := range partitions {
+		if partition.To == nil || partition.To.After(cutoff) {
+			continue
+		}
+		if err := m.repo.DropPartition(ctx, partition.Name); err != nil {
+			if errors.Is(err, repository.ErrPartitionBusy) {
+				// Retried at the next run rather than queueing inserts
+				// behind a long-running query
+				log.Printf("request logs: partition %s is in use, dropping it at the next run", partition.Name)
+				return nil
+			}
+			return err
+		}
+		log.Printf("request logs: dropped partition %s (before %s)", partition.Name, partition.To.Format(time.DateOnly))
+	}
+
+	if _, err := m.repo.DeleteDefaultBefore(ctx, cutoff); err != nil {
+		return err
+	}
+	return nil
+}
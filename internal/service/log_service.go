@@ -2,38 +2,83 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"fandom/notifications/internal/models"
 	"fandom/notifications/internal/repository"
 )
 
-type LogService struct {
-	repo *repository.LogRepository
+// maxStatsBuckets caps the length of the stats series.
+const maxStatsBuckets = 2000
+
+var ErrInvalidStatsBucket = errors.New("bucket must be one of: minute, hour, day")
+
+// ErrTooManyStatsBuckets is returned when the date range holds more than
+// maxStatsBuckets buckets.
+var ErrTooManyStatsBuckets = fmt.Errorf("date range spans more than %d buckets; use a larger bucket or a shorter range", maxStatsBuckets)
+
+var statsBucketSizes = map[string]time.Duration{
+	models.StatsBucketMinute: time.Minute,
+	models.StatsBucketHour:   time.Hour,
+	models.StatsBucketDay:    24 * time.Hour,
 }
 
-func NewLogService(repo *repository.LogRepository) *LogService {
-	return &LogService{repo: repo}
+type LogService struct {
+	repo       *repository.LogRepository
+	writer     *LogWriter
+	partitions *LogPartitionManager
 }
 
-func (s *LogService) LogRequest(ctx context.Context, log *models.RequestLog) error {
-	if log.CreatedAt.IsZero() {
-		log.CreatedAt = time.Now()
-	}
-	return s.repo.Create(ctx, log)
+func NewLogService(repo *repository.LogRepository, writer *LogWriter, partitions *LogPartitionManager) *LogService {
+	return &LogService{repo: repo, writer: writer, partitions: partitions}
 }
 
 func (s *LogService) GetLogs(ctx context.Context, params models.LogQueryParams) ([]models.RequestLog, int64, error) {
 	return s.repo.List(ctx, params)
 }
 
-func (s *LogService) GetStats(ctx context.Context, startDate, endDate time.Time) (*models.LogStats, error) {
+// GetStats aggregates the logs between startDate and endDate, the last 7
+// days by default. bucket sets the series interval; it defaults to hour, or
+// day for ranges over 31 days.
+func (s *LogService) GetStats(ctx context.Context, startDate, endDate time.Time, bucket string) (*models.LogStats, error) {
 	if startDate.IsZero() {
 		startDate = time.Now().AddDate(0, 0, -7) // Default to last 7 days
 	}
 	if endDate.IsZero() {
 		endDate = time.Now()
 	}
-	return s.repo.GetStats(ctx, startDate, endDate)
+
+	if bucket == "" {
+		bucket = models.StatsBucketHour
+		if endDate.Sub(startDate) > 31*24*time.Hour {
+			bucket = models.StatsBucketDay
+		}
+	}
+	size, ok := statsBucketSizes[bucket]
+	if !ok {
+		return nil, ErrInvalidStatsBucket
+	}
+	if endDate.Sub(startDate)/size >= maxStatsBuckets {
+		return nil, ErrTooManyStatsBuckets
+	}
+
+	stats, err := s.repo.GetStats(ctx, startDate, endDate, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	writerStats := s.writer.Stats()
+	stats.Writer = &writerStats
+	return stats, nil
+}
+
+// ExportLogs calls fn with every log matching params, oldest first.
+func (s *LogService) ExportLogs(ctx context.Context, params models.LogQueryParams, fn func(*models.RequestLog) error) error {
+	return s.repo.Export(ctx, params, fn)
 }
 
+func (s *LogService) GetPartitions(ctx context.Context) (*models.LogPartitionsResponse, error) {
+	return s.partitions.Partitions(ctx)
+}
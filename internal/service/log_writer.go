@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+)
+
+// logWriteTimeout bounds a single COPY of a batch.
+const logWriteTimeout = 10 * time.Second
+
+// LogWriter buffers request logs in memory and writes them in batches with
+// COPY, so logging a request never blocks it and costs no goroutine or
+// connection of its own. When the buffer is full new entries are dropped
+// and counted rather than slowing requests down.
+type LogWriter struct {
+	repo          *repository.LogRepository
+	entries       chan models.RequestLog
+	batchSize     int
+	flushInterval time.Duration
+
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+	written atomic.Uint64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewLogWriter buffers up to bufferSize entries and writes them once
+// batchSize have queued up or every flushInterval, whichever comes first.
+func NewLogWriter(repo *repository.LogRepository, bufferSize, batchSize int, flushInterval time.Duration) *LogWriter {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+
+	return &LogWriter{
+		repo:          repo,
+		entries:       make(chan models.RequestLog, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Write queues entry without blocking. It returns false if the buffer was
+// full and the entry was dropped.
+func (w *LogWriter) Write(entry models.RequestLog) bool {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	select {
+	case w.entries <- entry:
+		return true
+	default:
+		w.dropped.Add(1)
+		return false
+	}
+}
+
+// Stats returns the writer's counters since it was created.
+func (w *LogWriter) Stats() models.LogWriterStats {
+	return models.LogWriterStats{
+		Buffered: len(w.entries),
+		Written:  w.written.Load(),
+		Dropped:  w.dropped.Load(),
+		Failed:   w.failed.Load(),
+	}
+}
+
+// Start writes queued entries until Stop is called.
+func (w *LogWriter) Start() {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.flushInterval)
+		defer ticker.Stop()
+
+		batch := make([]models.RequestLog, 0, w.batchSize)
+		var reportedDrops uint64
+
+		for {
+			select {
+			case entry := <-w.entries:
+				batch = append(batch, entry)
+				if len(batch) >= w.batchSize {
+					batch = w.flush(batch)
+				}
+			case <-ticker.C:
+				batch = w.flush(batch)
+
+				if dropped := w.dropped.Load(); dropped > reportedDrops {
+					log.Printf("request logs: buffer full, dropped %d entries (%d in total)", dropped-reportedDrops, dropped)
+					reportedDrops = dropped
+				}
+			case <-w.stop:
+				// Write everything queued before Stop; entries arriving
+				// later stay in the buffer and are lost with the process.
+				for range len(w.entries) {
+					batch = append(batch, <-w.entries)
+					if len(batch) >= w.batchSize {
+						batch = w.flush(batch)
+					}
+				}
+				w.flush(batch)
+				return
+			}
+		}
+	}()
+}
+
+// Stop writes the buffered entries and returns once they are stored, or
+// when ctx expires. Call it after the HTTP server has shut down so no
+// requests are still logging.
+func (w *LogWriter) Stop(ctx context.Context) error {
+	close(w.stop)
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush writes batch and returns it emptied for reuse. Failed batches are
+// dropped: retrying would only let the buffer fill up behind them.
+func (w *LogWriter) flush(batch []models.RequestLog) []models.RequestLog {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), logWriteTimeout)
+	defer cancel()
+
+	written, err := w.repo.CreateBatch(ctx, batch)
+	if err != nil {
+		w.failed.Add(uint64(len(batch)))
+		log.Printf("request logs: failed to write %d entries: %v", len(batch), err)
+		return batch[:0]
+	}
+
+	w.written.Add(uint64(written))
+	return batch[:0]
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/realtime"
+	"fandom/notifications/internal/repository"
+)
+
+// ErrInvalidCursor is returned when an inbox cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type NotificationService struct {
+	repo     *repository.NotificationRepository
+	hub      *realtime.Hub
+	webhooks *WebhookService
+	push     *PushService
+}
+
+func NewNotificationService(repo *repository.NotificationRepository, hub *realtime.Hub, webhooks *WebhookService, push *PushService) *NotificationService {
+	return &NotificationService{repo: repo, hub: hub, webhooks: webhooks, push: push}
+}
+
+// PublishChapterReleased fans a chapter release out to every user bookmarking
+// the publication and returns the notifications that were created.
+func (s *NotificationService) PublishChapterReleased(ctx context.Context, event models.ChapterReleasedEvent) ([]models.Notification, error) {
+	notifications, err := s.repo.CreateForBookmarks(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hub.Publish(notifications...)
+
+	// The notifications are already stored, so a queueing failure must not
+	// fail the event: a retried event would find nothing new to deliver.
+	if err := s.webhooks.EnqueueChapterReleased(ctx, event, notifications); err != nil {
+		log.Printf("failed to enqueue webhooks for %s/%s: %v", event.PublicationID, event.ChapterID, err)
+	}
+	if err := s.push.Notify(ctx, notifications...); err != nil {
+		log.Printf("failed to enqueue push notifications for %s/%s: %v", event.PublicationID, event.ChapterID, err)
+	}
+
+	return notifications, nil
+}
+
+// Subscribe registers a real-time listener for the user's new notifications.
+// Callers must Close the subscription when done.
+func (s *NotificationService) Subscribe(userID string) *realtime.Subscription {
+	return s.hub.Subscribe(userID)
+}
+
+// ListSince returns up to limit of the user's notifications created after
+// afterID, oldest first, so a reconnecting client can catch up.
+func (s *NotificationService) ListSince(ctx context.Context, userID string, afterID int64, limit int) ([]models.Notification, error) {
+	return s.repo.ListAfter(ctx, userID, afterID, limit)
+}
+
+// ListInbox returns one page of a user's notifications, newest first. The
+// returned NextCursor is empty once the last page has been reached.
+func (s *NotificationService) ListInbox(ctx context.Context, params models.NotificationQueryParams) (*models.NotificationPage, error) {
+	if params.Limit <= 0 {
+		params.Limit = 50
+	}
+	if params.Limit > 200 {
+		params.Limit = 200
+	}
+
+	var beforeID int64
+	if params.Cursor != "" {
+		id, err := strconv.ParseInt(params.Cursor, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, ErrInvalidCursor
+		}
+		beforeID = id
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	notifications, err := s.repo.ListByUser(ctx, params.UserID, beforeID, params.Limit+1, params.UnreadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.NotificationPage{Notifications: notifications}
+	if len(notifications) > params.Limit {
+		page.Notifications = notifications[:params.Limit]
+		page.NextCursor = strconv.FormatInt(page.Notifications[params.Limit-1].ID, 10)
+	}
+
+	return page, nil
+}
+
+func (s *NotificationService) UnreadCount(ctx context.Context, userID string) (int64, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID string, ids []int64) (int64, error) {
+	return s.repo.MarkRead(ctx, userID, ids)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	return s.repo.MarkAllRead(ctx, userID)
+}
+
+func (s *NotificationService) DeleteNotification(ctx context.Context, userID string, id int64) (bool, error) {
+	return s.repo.Delete(ctx, userID, id)
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+	"fandom/notifications/internal/webpush"
+)
+
+const (
+	pushNotifyQueue = "push.notify"
+	pushSendQueue   = "push.send"
+	// pushTTL is how long push services keep a message for an offline browser.
+	pushTTL = 24 * time.Hour
+)
+
+var (
+	// ErrPushDisabled is returned when VAPID keys are not configured.
+	ErrPushDisabled = errors.New("web push is not configured")
+	// ErrInvalidPushSubscription is returned for malformed endpoints or keys.
+	ErrInvalidPushSubscription = errors.New("invalid push subscription")
+)
+
+// pushSendJob is the payload of a push.send job: one message for one
+// subscription, so a failing browser is retried without resending to others.
+type pushSendJob struct {
+	SubscriptionID int             `json:"subscription_id"`
+	Message        json.RawMessage `json:"message"`
+}
+
+// pushSubscriptionStore is the part of PushRepository the service uses.
+type pushSubscriptionStore interface {
+	Upsert(ctx context.Context, sub models.PushSubscription) (*models.PushSubscription, error)
+	ListByUser(ctx context.Context, userID string) ([]models.PushSubscription, error)
+	FindByID(ctx context.Context, id int) (*models.PushSubscription, error)
+	DeleteByEndpoint(ctx context.Context, endpoint string) (bool, error)
+	DeleteByID(ctx context.Context, id int) error
+	UpdateLastUsed(ctx context.Context, id int) error
+}
+
+// PushService stores browser push subscriptions and delivers new
+// notifications to them through the job queue. Without VAPID keys it still
+// accepts subscriptions but sends nothing.
+type PushService struct {
+	repo   pushSubscriptionStore
+	client *webpush.Client
+	jobs   *JobQueue
+}
+
+// NewPushService creates the service and registers its job handlers; client
+// may be nil to disable delivery.
+func NewPushService(repo *repository.PushRepository, client *webpush.Client, jobs *JobQueue) *PushService {
+	s := &PushService{repo: repo, client: client, jobs: jobs}
+
+	if client != nil {
+		jobs.Register(pushNotifyQueue, s.fanOut, JobQueueOptions{Workers: 2, Visibility: time.Minute})
+		jobs.Register(pushSendQueue, s.send, JobQueueOptions{Workers: 8, Visibility: time.Minute, MaxAttempts: 6})
+	}
+
+	return s
+}
+
+// PublicKey returns the VAPID application server key browsers pass to
+// pushManager.subscribe.
+func (s *PushService) PublicKey() (string, error) {
+	if s.client == nil {
+		return "", ErrPushDisabled
+	}
+	return s.client.Keys.PublicKey, nil
+}
+
+func (s *PushService) Subscribe(ctx context.Context, req models.RegisterPushSubscriptionRequest, userAgent string) (*models.PushSubscription, error) {
+	endpoint, err := url.Parse(req.Subscription.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: endpoint must be an https URL", ErrInvalidPushSubscription)
+	}
+	if err := webpush.ValidateKeys(req.Subscription.Keys.P256dh, req.Subscription.Keys.Auth); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPushSubscription, err)
+	}
+
+	return s.repo.Upsert(ctx, models.PushSubscription{
+		UserID:    req.UserID,
+		Endpoint:  req.Subscription.Endpoint,
+		P256dh:    req.Subscription.Keys.P256dh,
+		Auth:      req.Subscription.Keys.Auth,
+		UserAgent: userAgent,
+	})
+}
+
+func (s *PushService) Unsubscribe(ctx context.Context, endpoint string) (bool, error) {
+	return s.repo.DeleteByEndpoint(ctx, endpoint)
+}
+
+func (s *PushService) ListSubscriptions(ctx context.Context, userID string) ([]models.PushSubscription, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Notify queues delivery of notifications to their users' browsers.
+func (s *PushService) Notify(ctx context.Context, notifications ...models.Notification) error {
+	if s.client == nil || len(notifications) == 0 {
+		return nil
+	}
+
+	payloads := make([]any, len(notifications))
+	for i, n := range notifications {
+		payloads[i] = n
+	}
+
+	return s.jobs.Enqueue(ctx, pushNotifyQueue, payloads...)
+}
+
+// fanOut turns one notification into a push.send job per subscription of its
+// user.
+func (s *PushService) fanOut(ctx context.Context, job models.Job) error {
+	var n models.Notification
+	if err := json.Unmarshal(job.Payload, &n); err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanentJobFailure, err)
+	}
+
+	subs, err := s.repo.ListByUser(ctx, n.UserID)
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	message, err := json.Marshal(buildPushMessage(n))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanentJobFailure, err)
+	}
+
+	payloads := make([]any, len(subs))
+	for i, sub := range subs {
+		payloads[i] = pushSendJob{SubscriptionID: sub.ID, Message: message}
+	}
+
+	return s.jobs.Enqueue(ctx, pushSendQueue, payloads...)
+}
+
+// send delivers one message. Subscriptions the push service no longer knows
+// are deleted; messages too large to send, redirects and other client errors
+// are not retried.
+func (s *PushService) send(ctx context.Context, job models.Job) error {
+	var payload pushSendJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanentJobFailure, err)
+	}
+
+	sub, err := s.repo.FindByID(ctx, payload.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	if sub == nil {
+		// Unsubscribed since the job was queued
+		return nil
+	}
+
+	err = s.client.Send(ctx, webpush.Subscription{
+		Endpoint: sub.Endpoint,
+		P256dh:   sub.P256dh,
+		Auth:     sub.Auth,
+	}, payload.Message, pushTTL)
+
+	var statusErr *webpush.StatusError
+	switch {
+	case errors.Is(err, webpush.ErrSubscriptionGone):
+		return s.repo.DeleteByID(ctx, sub.ID)
+	case errors.Is(err, webpush.ErrPayloadTooLarge):
+		// The message will not get any smaller on a retry
+		return fmt.Errorf("%w: %w", ErrPermanentJobFailure, err)
+	case errors.As(err, &statusErr) && statusErr.StatusCode >= 300 && statusErr.StatusCode < 500 &&
+		statusErr.StatusCode != http.StatusRequestTimeout && statusErr.StatusCode != http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %v", ErrPermanentJobFailure, err)
+	case err != nil:
+		return err
+	}
+
+	if err := s.repo.UpdateLastUsed(ctx, sub.ID); err != nil {
+		log.Printf("push: failed to update subscription %d: %v", sub.ID, err)
+	}
+	return nil
+}
+
+func buildPushMessage(n models.Notification) models.PushMessage {
+	title := n.Name
+	if title == "" {
+		title = n.PublicationID
+	}
+
+	body := "A new chapter is out"
+	switch {
+	case n.Volume != "" && n.Chapter != "":
+		body = fmt.Sprintf("Vol. %s Ch. %s is out", n.Volume, n.Chapter)
+	case n.Chapter != "":
+		body = fmt.Sprintf("Chapter %s is out", n.Chapter)
+	}
+
+	return models.PushMessage{
+		Title:        title,
+		Body:         body,
+		Image:        n.Image,
+		Notification: n,
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/webpush"
+)
+
+// fakePushStore serves a single subscription and records what happens to it.
+type fakePushStore struct {
+	sub      models.PushSubscription
+	deleted  []int
+	lastUsed []int
+}
+
+func (s *fakePushStore) Upsert(ctx context.Context, sub models.PushSubscription) (*models.PushSubscription, error) {
+	return &sub, nil
+}
+
+func (s *fakePushStore) ListByUser(ctx context.Context, userID string) ([]models.PushSubscription, error) {
+	return []models.PushSubscription{s.sub}, nil
+}
+
+func (s *fakePushStore) FindByID(ctx context.Context, id int) (*models.PushSubscription, error) {
+	if id != s.sub.ID {
+		return nil, nil
+	}
+	sub := s.sub
+	return &sub, nil
+}
+
+func (s *fakePushStore) DeleteByEndpoint(ctx context.Context, endpoint string) (bool, error) {
+	return false, nil
+}
+
+func (s *fakePushStore) DeleteByID(ctx context.Context, id int) error {
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+func (s *fakePushStore) UpdateLastUsed(ctx context.Context, id int) error {
+	s.lastUsed = append(s.lastUsed, id)
+	return nil
+}
+
+func TestPushServiceSendHandlesPushServiceResponses(t *testing.T) {
+	keys, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	browserKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	_, _ = rand.Read(auth)
+
+	job := models.Job{Payload: json.RawMessage(`{"subscription_id": 42, "message": {"title": "Solo Leveling"}}`)}
+
+	tests := []struct {
+		name          string
+		status        int
+		wantDeleted   bool
+		wantLastUsed  bool
+		wantErr       bool
+		wantPermanent bool
+	}{
+		{"delivered", http.StatusCreated, false, true, false, false},
+		{"not found", http.StatusNotFound, true, false, false, false},
+		{"gone", http.StatusGone, true, false, false, false},
+		{"redirected", http.StatusFound, false, false, true, true},
+		{"rejected", http.StatusBadRequest, false, false, true, true},
+		{"rate limited", http.StatusTooManyRequests, false, false, true, false},
+		{"unavailable", http.StatusServiceUnavailable, false, false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			store := &fakePushStore{sub: models.PushSubscription{
+				ID:       42,
+				Endpoint: server.URL,
+				P256dh:   base64.RawURLEncoding.EncodeToString(browserKey.PublicKey().Bytes()),
+				Auth:     base64.RawURLEncoding.EncodeToString(auth),
+			}}
+			client := webpush.NewClient(keys, "mailto:ops@fandom.example")
+			client.HTTPClient = server.Client()
+			s := &PushService{repo: store, client: client}
+
+			err := s.send(context.Background(), job)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("send() = %v, want error %v", err, tt.wantErr)
+			}
+			if permanent := errors.Is(err, ErrPermanentJobFailure); permanent != tt.wantPermanent {
+				t.Errorf("permanent = %v, want %v (err %v)", permanent, tt.wantPermanent, err)
+			}
+			if deleted := len(store.deleted) == 1 && store.deleted[0] == 42; deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want subscription deleted %v", store.deleted, tt.wantDeleted)
+			}
+			if lastUsed := len(store.lastUsed) == 1; lastUsed != tt.wantLastUsed {
+				t.Errorf("lastUsed = %v, want updated %v", store.lastUsed, tt.wantLastUsed)
+			}
+		})
+	}
+}
+
+func TestPushServiceSendDropsOversizedMessages(t *testing.T) {
+	keys, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	browserKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	_, _ = rand.Read(auth)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("oversized message reached the push service")
+	}))
+	defer server.Close()
+
+	store := &fakePushStore{sub: models.PushSubscription{
+		ID:       42,
+		Endpoint: server.URL,
+		P256dh:   base64.RawURLEncoding.EncodeToString(browserKey.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(auth),
+	}}
+	client := webpush.NewClient(keys, "mailto:ops@fandom.example")
+	client.HTTPClient = server.Client()
+	s := &PushService{repo: store, client: client}
+
+	message, _ := json.Marshal(buildPushMessage(models.Notification{
+		Name:  "Solo Leveling",
+		Image: "https://cdn.example/" + strings.Repeat("a", webpush.MaxPayloadSize),
+	}))
+	payload, _ := json.Marshal(pushSendJob{SubscriptionID: 42, Message: message})
+
+	err = s.send(context.Background(), models.Job{Payload: payload})
+	if !errors.Is(err, ErrPermanentJobFailure) || !errors.Is(err, webpush.ErrPayloadTooLarge) {
+		t.Errorf("send() = %v, want a permanent ErrPayloadTooLarge failure", err)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/repository"
+)
+
+// ErrInvalidCredentials is returned when a login uses a key that matches no
+// active admin or API key.
+var ErrInvalidCredentials = errors.New("invalid or inactive key")
+
+// SessionService logs browsers in with an admin or API key. The browser gets
+// an opaque session token instead of the key; the session follows its
+// principal, so revoking the key or disabling the admin ends it too.
+type SessionService struct {
+	repo    *repository.SessionRepository
+	admins  *AdminService
+	apiKeys *APIKeyService
+	ttl     time.Duration
+}
+
+func NewSessionService(repo *repository.SessionRepository, admins *AdminService, apiKeys *APIKeyService, ttl time.Duration) *SessionService {
+	return &SessionService{repo: repo, admins: admins, apiKeys: apiKeys, ttl: ttl}
+}
+
+// Login validates key and starts a session for its admin or API key,
+// returning the token to hand to the browser. Invalid keys return
+// ErrInvalidCredentials and expired API keys ErrAPIKeyExpired.
+func (s *SessionService) Login(ctx context.Context, key, userAgent, ipAddress string) (string, *models.Session, error) {
+	var adminID, apiKeyID *int
+
+	admin, err := s.admins.Authenticate(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	if admin != nil {
+		adminID = &admin.ID
+	} else {
+		apiKey, err := s.apiKeys.ValidateKey(ctx, key)
+		if err != nil {
+			return "", nil, err
+		}
+		if apiKey == nil {
+			return "", nil, ErrInvalidCredentials
+		}
+		apiKeyID = &apiKey.ID
+	}
+
+	token, err := generateKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	session, err := s.repo.Create(ctx, HashAPIKey(token), adminID, apiKeyID, userAgent, ipAddress, s.ttl)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Logins are rare, so this is a cheap place to drop expired sessions
+	if _, err := s.repo.DeleteExpired(ctx); err != nil {
+		log.Printf("sessions: failed to delete expired sessions: %v", err)
+	}
+
+	return token, session, nil
+}
+
+// Resolve returns the principal of the session with token: exactly one of
+// the admin and the API key is set. Both are nil for unknown or expired
+// sessions and for sessions whose admin or key can no longer be used.
+func (s *SessionService) Resolve(ctx context.Context, token string) (*models.Admin, *models.APIKey, error) {
+	session, err := s.repo.FindActive(ctx, HashAPIKey(token))
+	if err != nil || session == nil {
+		return nil, nil, err
+	}
+
+	if session.AdminID != nil {
+		admin, err := s.admins.FindActiveAdmin(ctx, *session.AdminID)
+		return admin, nil, err
+	}
+
+	apiKey, err := s.apiKeys.FindUsableKey(ctx, *session.APIKeyID)
+	return nil, apiKey, err
+}
+
+// Logout ends the session with token. Unknown tokens are ignored.
+func (s *SessionService) Logout(ctx context.Context, token string) error {
+	return s.repo.Delete(ctx, HashAPIKey(token))
+}
+
+// CSRFToken returns the CSRF token of the session with sessionToken. It is
+// derived from the session token, so it needs no storage, changes with every
+// login and cannot be computed by pages that can't read the HttpOnly cookie.
+func CSRFToken(sessionToken string) string {
+	mac := hmac.New(sha256.New, []byte(sessionToken))
+	mac.Write([]byte("csrf"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidCSRFToken reports whether csrfToken belongs to the session with
+// sessionToken.
+func ValidCSRFToken(sessionToken, csrfToken string) bool {
+	return csrfToken != "" && hmac.Equal([]byte(CSRFToken(sessionToken)), []byte(csrfToken))
+}
+
+// NewCSRFToken returns a random token for forms used before a session
+// exists, such as the login form.
+func NewCSRFToken() (string, error) {
+	return generateKey()
+}
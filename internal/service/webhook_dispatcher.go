@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/netguard"
+	"fandom/notifications/internal/repository"
+)
+
+const (
+	webhookPollInterval   = 5 * time.Second
+	webhookBatchSize      = 50
+	webhookRequestTimeout = 10 * time.Second
+	// webhookLease hides a claimed delivery from other workers while it is
+	// being sent; it must comfortably exceed webhookRequestTimeout.
+	webhookLease       = time.Minute
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = 6 * time.Hour
+)
+
+// webhookDeliveryStore is the part of WebhookRepository the dispatcher uses.
+type webhookDeliveryStore interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, attempt *models.WebhookDeliveryAttempt) error
+	MarkDelivered(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, lastError string, retryIn time.Duration, dead bool) error
+}
+
+// WebhookDispatcher sends pending webhook deliveries in the background,
+// retrying failures with exponential backoff until MaxAttempts is reached.
+type WebhookDispatcher struct {
+	repo        webhookDeliveryStore
+	client      *http.Client
+	maxAttempts int
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewWebhookDispatcher sends deliveries with client, or with a client that
+// refuses to connect to internal addresses when client is nil.
+func NewWebhookDispatcher(repo *repository.WebhookRepository, client *http.Client, maxAttempts int) *WebhookDispatcher {
+	if client == nil {
+		client = newWebhookHTTPClient()
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	return &WebhookDispatcher{
+		repo:        repo,
+		client:      client,
+		maxAttempts: maxAttempts,
+		stop:        make(chan struct{}),
+	}
+}
+
+// newWebhookHTTPClient returns a client that refuses to connect to internal
+// addresses.
+func newWebhookHTTPClient() *http.Client {
+	return netguard.NewHTTPClient(webhookRequestTimeout)
+}
+
+// Start polls for due deliveries until Stop is called.
+func (d *WebhookDispatcher) Start() {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+
+		for {
+			d.dispatchDue()
+
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop waits for the batch in flight to finish, or for ctx to expire.
+// Unfinished deliveries are picked up again once their lease runs out.
+func (d *WebhookDispatcher) Stop(ctx context.Context) error {
+	close(d.stop)
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *WebhookDispatcher) dispatchDue() {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookLease)
+	defer cancel()
+
+	deliveries, err := d.repo.ClaimDue(ctx, webhookBatchSize, webhookLease)
+	if err != nil {
+		log.Printf("webhooks: failed to claim deliveries: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery models.WebhookDelivery) {
+			defer wg.Done()
+			d.process(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+}
+
+func (d *WebhookDispatcher) process(ctx context.Context, delivery models.WebhookDelivery) {
+	start := time.Now()
+	statusCode, sendErr := d.Send(ctx, delivery)
+
+	attempt := &models.WebhookDeliveryAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		StatusCode: statusCode,
+		Duration:   time.Since(start).Milliseconds(),
+	}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	}
+	if err := d.repo.RecordAttempt(ctx, attempt); err != nil {
+		log.Printf("webhooks: failed to record attempt for delivery %d: %v", delivery.ID, err)
+	}
+
+	if sendErr == nil {
+		if err := d.repo.MarkDelivered(ctx, delivery.ID); err != nil {
+			log.Printf("webhooks: failed to mark delivery %d delivered: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	dead := delivery.Attempts >= d.maxAttempts
+	if err := d.repo.MarkFailed(ctx, delivery.ID, sendErr.Error(), webhookBackoff(delivery.Attempts), dead); err != nil {
+		log.Printf("webhooks: failed to mark delivery %d failed: %v", delivery.ID, err)
+	}
+}
+
+// Send POSTs the delivery's payload to its subscription URL. Any 2xx response
+// counts as delivered. The returned status code is 0 if no response arrived.
+func (d *WebhookDispatcher) Send(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fandom-notifications-webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", SignWebhookPayload(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the X-Webhook-Signature header value:
+// "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with
+// the subscription secret. Receivers should recompute it and reject stale
+// timestamps to prevent replays.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff doubles the delay after every failed attempt, capped at
+// webhookMaxBackoff, with up to 10% jitter so retries don't synchronize.
+func webhookBackoff(attempt int) time.Duration {
+	backoff := webhookMaxBackoff
+	if attempt < 20 {
+		backoff = min(webhookBaseBackoff<<(attempt-1), webhookMaxBackoff)
+	}
+	return backoff + rand.N(backoff/10+1)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/netguard"
+)
+
+// fakeDeliveryStore hands out the deliveries it holds once and records what
+// the dispatcher reports back.
+type fakeDeliveryStore struct {
+	mu        sync.Mutex
+	due       []models.WebhookDelivery
+	attempts  []models.WebhookDeliveryAttempt
+	delivered []int64
+	failed    []failedDelivery
+}
+
+type failedDelivery struct {
+	id      int64
+	retryIn time.Duration
+	dead    bool
+}
+
+func (s *fakeDeliveryStore) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	due := s.due
+	s.due = nil
+	return due, nil
+}
+
+func (s *fakeDeliveryStore) RecordAttempt(ctx context.Context, attempt *models.WebhookDeliveryAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, *attempt)
+	return nil
+}
+
+func (s *fakeDeliveryStore) MarkDelivered(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delivered = append(s.delivered, id)
+	return nil
+}
+
+func (s *fakeDeliveryStore) MarkFailed(ctx context.Context, id int64, lastError string, retryIn time.Duration, dead bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = append(s.failed, failedDelivery{id: id, retryIn: retryIn, dead: dead})
+	return nil
+}
+
+func newTestDispatcher(server *httptest.Server, store *fakeDeliveryStore, maxAttempts int) *WebhookDispatcher {
+	d := NewWebhookDispatcher(nil, server.Client(), maxAttempts)
+	d.repo = store
+	return d
+}
+
+func TestWebhookDispatcherSignsDeliveries(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header.Clone(), body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	payload := []byte(`{"event":"chapter.released","data":{"chapter_id":"c-1"}}`)
+	store := &fakeDeliveryStore{due: []models.WebhookDelivery{{
+		ID:        7,
+		EventType: models.WebhookEventChapterReleased,
+		Payload:   payload,
+		Attempts:  1,
+		URL:       server.URL,
+		Secret:    "whsec_test",
+	}}}
+
+	newTestDispatcher(server, store, 3).dispatchDue()
+
+	req := <-requests
+	if string(req.body) != string(payload) {
+		t.Errorf("body = %s, want %s", req.body, payload)
+	}
+	if got := req.header.Get("X-Webhook-Id"); got != "7" {
+		t.Errorf("X-Webhook-Id = %q, want 7", got)
+	}
+	if got := req.header.Get("X-Webhook-Event"); got != models.WebhookEventChapterReleased {
+		t.Errorf("X-Webhook-Event = %q", got)
+	}
+
+	timestamp, err := strconv.ParseInt(req.header.Get("X-Webhook-Timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("X-Webhook-Timestamp: %v", err)
+	}
+	if want := SignWebhookPayload("whsec_test", timestamp, payload); req.header.Get("X-Webhook-Signature") != want {
+		t.Errorf("X-Webhook-Signature = %q, want %q", req.header.Get("X-Webhook-Signature"), want)
+	}
+
+	if len(store.delivered) != 1 || store.delivered[0] != 7 {
+		t.Errorf("delivered = %v, want [7]", store.delivered)
+	}
+	if len(store.failed) != 0 {
+		t.Errorf("failed = %v, want none", store.failed)
+	}
+	if len(store.attempts) != 1 || store.attempts[0].StatusCode != http.StatusNoContent {
+		t.Errorf("attempts = %+v, want one with status 204", store.attempts)
+	}
+}
+
+func TestWebhookDispatcherRetriesAndDeadLetters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	const maxAttempts = 4
+	tests := []struct {
+		name       string
+		attempts   int
+		minBackoff time.Duration
+		dead       bool
+	}{
+		{"first failure", 1, 30 * time.Second, false},
+		{"third failure", 3, 2 * time.Minute, false},
+		{"last attempt", maxAttempts, 4 * time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeDeliveryStore{due: []models.WebhookDelivery{{
+				ID:       1,
+				Payload:  []byte(`{}`),
+				Attempts: tt.attempts,
+				URL:      server.URL,
+			}}}
+
+			newTestDispatcher(server, store, maxAttempts).dispatchDue()
+
+			if len(store.delivered) != 0 {
+				t.Fatalf("delivered = %v, want none", store.delivered)
+			}
+			if len(store.failed) != 1 {
+				t.Fatalf("failed = %v, want one", store.failed)
+			}
+			failed := store.failed[0]
+			if failed.dead != tt.dead {
+				t.Errorf("dead = %v, want %v", failed.dead, tt.dead)
+			}
+			if maxBackoff := tt.minBackoff + tt.minBackoff/10; failed.retryIn < tt.minBackoff || failed.retryIn > maxBackoff {
+				t.Errorf("retryIn = %v, want between %v and %v", failed.retryIn, tt.minBackoff, maxBackoff)
+			}
+			if len(store.attempts) != 1 || store.attempts[0].StatusCode != http.StatusServiceUnavailable || store.attempts[0].Error == "" {
+				t.Errorf("attempts = %+v, want one failed with status 503", store.attempts)
+			}
+		})
+	}
+}
+
+func TestWebhookBackoffIsCapped(t *testing.T) {
+	for _, attempt := range []int{15, 20, 64} {
+		if backoff := webhookBackoff(attempt); backoff < webhookMaxBackoff || backoff > webhookMaxBackoff+webhookMaxBackoff/10 {
+			t.Errorf("webhookBackoff(%d) = %v, want about %v", attempt, backoff, webhookMaxBackoff)
+		}
+	}
+}
+
+func TestWebhookClientRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the loopback server")
+	}))
+	defer server.Close()
+
+	_, err := newWebhookHTTPClient().Get(server.URL)
+	if !errors.Is(err, netguard.ErrAddressNotAllowed) {
+		t.Fatalf("err = %v, want netguard.ErrAddressNotAllowed", err)
+	}
+}
+
+func TestCheckWebhookHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"::ffff:127.0.0.1", false},
+		{"0.1.2.3", false},
+		{"100.64.0.1", false},
+		{"100.100.100.200", false},
+		{"198.18.0.1", false},
+		{"198.19.255.255", false},
+		{"255.255.255.255", false},
+		{"64:ff9b::a9fe:a9fe", false},
+		{"64:ff9b::7f00:1", false},
+		{"2002:7f00:1::", false},
+		{"::ffff:100.64.0.1", false},
+		{"localhost", false},
+		{"93.184.216.34", true},
+		{"100.128.0.1", true},
+		{"198.20.0.1", true},
+		{"2606:4700::1111", true},
+	}
+
+	for _, tt := range tests {
+		err := checkWebhookHost(context.Background(), tt.host)
+		if tt.allowed && err != nil {
+			t.Errorf("checkWebhookHost(%q) = %v, want nil", tt.host, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrWebhookURLNotAllowed) {
+			t.Errorf("checkWebhookHost(%q) = %v, want ErrWebhookURLNotAllowed", tt.host, err)
+		}
+	}
+}
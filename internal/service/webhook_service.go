@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"time"
+
+	"fandom/notifications/internal/models"
+	"fandom/notifications/internal/netguard"
+	"fandom/notifications/internal/repository"
+)
+
+var (
+	ErrInvalidWebhookURL       = errors.New("webhook url must be an absolute http or https URL")
+	ErrUnknownWebhookEventType = errors.New("unknown webhook event type")
+	// ErrWebhookURLNotAllowed is returned for URLs whose host is or resolves
+	// to an address the dispatcher refuses to connect to.
+	ErrWebhookURLNotAllowed = errors.New("webhook url must not point to a loopback, private, link-local or other internal address")
+)
+
+type WebhookService struct {
+	repo *repository.WebhookRepository
+}
+
+func NewWebhookService(repo *repository.WebhookRepository) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// CreateSubscription registers a webhook for the API key. A signing secret is
+// generated when none is supplied; it is only returned from this call.
+func (s *WebhookService) CreateSubscription(ctx context.Context, apiKeyID int, req models.CreateWebhookRequest) (*models.WebhookSubscription, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+	if err := checkWebhookHost(ctx, u.Hostname()); err != nil {
+		return nil, err
+	}
+
+	for _, eventType := range req.EventTypes {
+		if !slices.Contains(models.WebhookEventTypes, eventType) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownWebhookEventType, eventType)
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		bytes := make([]byte, 32)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, fmt.Errorf("failed to generate random bytes: %w", err)
+		}
+		secret = "whsec_" + hex.EncodeToString(bytes)
+	}
+
+	return s.repo.CreateSubscription(ctx, apiKeyID, req.URL, secret, slices.Compact(slices.Sorted(slices.Values(req.EventTypes))))
+}
+
+// checkWebhookHost rejects hosts that are, or resolve to, internal
+// addresses. The dispatcher checks again when it connects, since DNS answers
+// can change after the subscription is created.
+func checkWebhookHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !netguard.IPAllowed(ip) {
+			return ErrWebhookURLNotAllowed
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve %s", ErrInvalidWebhookURL, host)
+	}
+	for _, addr := range addrs {
+		if !netguard.IPAllowed(addr.IP) {
+			return ErrWebhookURLNotAllowed
+		}
+	}
+	return nil
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context, apiKeyID int) ([]models.WebhookSubscription, error) {
+	return s.repo.ListSubscriptions(ctx, apiKeyID)
+}
+
+func (s *WebhookService) DeleteSubscription(ctx context.Context, apiKeyID, id int) (bool, error) {
+	return s.repo.DeleteSubscription(ctx, apiKeyID, id)
+}
+
+// EnqueueChapterReleased queues a chapter.released delivery for the event and a
+// notification.created delivery per notification for the matching subscribers.
+// Replays of an event create neither again: notifications holds only newly
+// created notifications, and chapter.released is deduplicated per chapter.
+func (s *WebhookService) EnqueueChapterReleased(ctx context.Context, event models.ChapterReleasedEvent, notifications []models.Notification) error {
+	now := time.Now().UTC()
+
+	payload, err := json.Marshal(models.WebhookPayload{
+		Event:     models.WebhookEventChapterReleased,
+		CreatedAt: now,
+		Data:      event,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Replaying an event is safe, so the chapter.released delivery is only
+	// created once per subscription and chapter
+	dedupeKey := event.PublicationID + "/" + event.ChapterID
+	if _, err := s.repo.EnqueueOnce(ctx, models.WebhookEventChapterReleased, dedupeKey, string(payload)); err != nil {
+		return err
+	}
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	payloads := make([]string, 0, len(notifications))
+	for _, n := range notifications {
+		payload, err := json.Marshal(models.WebhookPayload{
+			Event:     models.WebhookEventNotificationCreated,
+			CreatedAt: now,
+			Data:      n,
+		})
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, string(payload))
+	}
+
+	_, err = s.repo.Enqueue(ctx, models.WebhookEventNotificationCreated, payloads)
+	return err
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, params models.WebhookDeliveryQueryParams) ([]models.WebhookDelivery, int64, error) {
+	return s.repo.ListDeliveries(ctx, params)
+}
+
+// GetDelivery returns a delivery with its attempts, or nil if it doesn't exist.
+func (s *WebhookService) GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, []models.WebhookDeliveryAttempt, error) {
+	delivery, err := s.repo.FindDelivery(ctx, id)
+	if err != nil || delivery == nil {
+		return nil, nil, err
+	}
+
+	attempts, err := s.repo.ListAttempts(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return delivery, attempts, nil
+}
+
+// RetryDelivery requeues a dead delivery. It reports false if the delivery
+// doesn't exist or isn't dead.
+func (s *WebhookService) RetryDelivery(ctx context.Context, id int64) (bool, error) {
+	return s.repo.Requeue(ctx, id)
+}
@@ -0,0 +1,99 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fandom/notifications/internal/netguard"
+)
+
+// ErrSubscriptionGone is returned when the push service reports that the
+// subscription no longer exists (404 or 410); it should be deleted.
+var ErrSubscriptionGone = errors.New("push subscription is gone")
+
+// StatusError is returned when the push service rejects a message.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("push service responded with status %d", e.StatusCode)
+}
+
+// Subscription is the part of a browser PushSubscription needed to send to it.
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// Client sends encrypted messages to push services.
+type Client struct {
+	HTTPClient *http.Client
+	Keys       *VAPIDKeys
+	// Subject is the contact for the push service operator, a mailto: or
+	// https: URL.
+	Subject string
+}
+
+// NewClient returns a client that refuses to connect to internal addresses,
+// since endpoints come from API clients, and does not follow redirects, which
+// push services never send.
+func NewClient(keys *VAPIDKeys, subject string) *Client {
+	httpClient := netguard.NewHTTPClient(10 * time.Second)
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &Client{
+		HTTPClient: httpClient,
+		Keys:       keys,
+		Subject:    subject,
+	}
+}
+
+// Send encrypts payload for the subscription and posts it to its endpoint.
+// ttl is how long the push service should hold the message for an offline
+// browser.
+func (c *Client) Send(ctx context.Context, sub Subscription, payload []byte, ttl time.Duration) error {
+	body, err := Encrypt(sub.P256dh, sub.Auth, payload)
+	if err != nil {
+		return err
+	}
+
+	authorization, err := c.Keys.AuthorizationHeader(sub.Endpoint, c.Subject, 12*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
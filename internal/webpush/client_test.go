@@ -0,0 +1,321 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fandom/notifications/internal/netguard"
+)
+
+// testBrowser holds the keys a browser creates for a push subscription.
+type testBrowser struct {
+	private *ecdh.PrivateKey
+	auth    []byte
+}
+
+func newTestBrowser(t *testing.T) *testBrowser {
+	t.Helper()
+
+	private, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := make([]byte, 16)
+	if _, err := rand.Read(auth); err != nil {
+		t.Fatal(err)
+	}
+	return &testBrowser{private: private, auth: auth}
+}
+
+func (b *testBrowser) subscription(endpoint string) Subscription {
+	return Subscription{
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(b.private.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(b.auth),
+	}
+}
+
+// decrypt reverses Encrypt the way a browser does (RFC 8291 and RFC 8188).
+func (b *testBrowser) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+
+	if len(body) < headerSize {
+		t.Fatalf("body of %d bytes is shorter than the header", len(body))
+	}
+	salt := body[:16]
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != recordSize {
+		t.Errorf("record size = %d, want %d", rs, recordSize)
+	}
+	keyIDLength := int(body[20])
+	asPublicBytes := body[21 : 21+keyIDLength]
+	ciphertext := body[21+keyIDLength:]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	if err != nil {
+		t.Fatalf("key id is not a P-256 public key: %v", err)
+	}
+	ecdhSecret, err := b.private.ECDH(asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyInfo := "WebPush: info\x00" + string(b.private.PublicKey().Bytes()) + string(asPublicBytes)
+	ikm, err := hkdf.Key(sha256.New, ecdhSecret, b.auth, keyInfo, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+
+	if len(record) == 0 || record[len(record)-1] != 0x02 {
+		t.Fatalf("record does not end with the final-record delimiter")
+	}
+	return record[:len(record)-1]
+}
+
+// verifyVAPID checks an Authorization header against RFC 8292 and returns the
+// token's claims.
+func verifyVAPID(t *testing.T, header, publicKey string) map[string]any {
+	t.Helper()
+
+	rest, ok := strings.CutPrefix(header, "vapid t=")
+	if !ok {
+		t.Fatalf("Authorization = %q, want the vapid scheme", header)
+	}
+	token, k, ok := strings.Cut(rest, ", k=")
+	if !ok {
+		t.Fatalf("Authorization = %q has no k parameter", header)
+	}
+	if k != publicKey {
+		t.Errorf("k = %q, want %q", k, publicKey)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts, want 3", len(parts))
+	}
+
+	var jwtHeader map[string]string
+	decodeJSONPart(t, parts[0], &jwtHeader)
+	if jwtHeader["alg"] != "ES256" {
+		t.Errorf("alg = %q, want ES256", jwtHeader["alg"])
+	}
+
+	keyBytes, err := base64.RawURLEncoding.DecodeString(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), keyBytes)
+	if err != nil {
+		t.Fatalf("k is not a P-256 public key: %v", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(sig) != 64 {
+		t.Fatalf("signature is not 64 bytes of base64url: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !ecdsa.Verify(key, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+		t.Fatal("VAPID signature does not verify")
+	}
+
+	var claims map[string]any
+	decodeJSONPart(t, parts[1], &claims)
+	return claims
+}
+
+func decodeJSONPart(t *testing.T, part string, v any) {
+	t.Helper()
+
+	raw, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientSendEncryptsAndSigns(t *testing.T) {
+	keys, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	browser := newTestBrowser(t)
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := newTestClient(keys, server)
+	payload := []byte(`{"title":"Solo Leveling","body":"Chapter 180 is out"}`)
+	if err := client.Send(context.Background(), browser.subscription(server.URL+"/push/abc"), payload, time.Hour); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if received.Method != http.MethodPost || received.URL.Path != "/push/abc" {
+		t.Errorf("request = %s %s", received.Method, received.URL.Path)
+	}
+	if got := received.Header.Get("Content-Encoding"); got != "aes128gcm" {
+		t.Errorf("Content-Encoding = %q", got)
+	}
+	if got := received.Header.Get("TTL"); got != "3600" {
+		t.Errorf("TTL = %q, want 3600", got)
+	}
+
+	if got := browser.decrypt(t, body); !bytes.Equal(got, payload) {
+		t.Errorf("decrypted payload = %q, want %q", got, payload)
+	}
+
+	claims := verifyVAPID(t, received.Header.Get("Authorization"), keys.PublicKey)
+	if claims["aud"] != server.URL {
+		t.Errorf("aud = %v, want %s", claims["aud"], server.URL)
+	}
+	if claims["sub"] != "mailto:ops@fandom.example" {
+		t.Errorf("sub = %v", claims["sub"])
+	}
+	exp, _ := claims["exp"].(float64)
+	if remaining := time.Until(time.Unix(int64(exp), 0)); remaining <= 0 || remaining > 24*time.Hour {
+		t.Errorf("exp is %v from now, want within 24h (RFC 8292)", remaining)
+	}
+}
+
+func TestClientSendReportsGoneSubscriptions(t *testing.T) {
+	keys, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	browser := newTestBrowser(t)
+
+	tests := []struct {
+		status   int
+		wantGone bool
+	}{
+		{http.StatusNotFound, true},
+		{http.StatusGone, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+
+		err := newTestClient(keys, server).Send(context.Background(), browser.subscription(server.URL), []byte("{}"), time.Minute)
+		server.Close()
+
+		if gone := errors.Is(err, ErrSubscriptionGone); gone != tt.wantGone {
+			t.Errorf("status %d: err = %v, want gone %v", tt.status, err, tt.wantGone)
+		}
+		var statusErr *StatusError
+		if !tt.wantGone && (!errors.As(err, &statusErr) || statusErr.StatusCode != tt.status) {
+			t.Errorf("status %d: err = %v, want a StatusError", tt.status, err)
+		}
+	}
+}
+
+// newTestClient returns a NewClient that may connect to the loopback test
+// server.
+func newTestClient(keys *VAPIDKeys, server *httptest.Server) *Client {
+	client := NewClient(keys, "mailto:ops@fandom.example")
+	client.HTTPClient.Transport = server.Client().Transport
+	return client
+}
+
+func TestClientRefusesInternalAddressesAndRedirects(t *testing.T) {
+	keys, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	browser := newTestBrowser(t)
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the redirect target")
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	// Endpoints come from API clients, so the default client does not
+	// connect to the loopback server at all
+	err = NewClient(keys, "mailto:ops@fandom.example").Send(context.Background(), browser.subscription(server.URL), []byte("{}"), time.Minute)
+	if !errors.Is(err, netguard.ErrAddressNotAllowed) {
+		t.Errorf("loopback endpoint: err = %v, want netguard.ErrAddressNotAllowed", err)
+	}
+
+	// Redirects are reported rather than followed
+	err = newTestClient(keys, server).Send(context.Background(), browser.subscription(server.URL), []byte("{}"), time.Minute)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("redirect: err = %v, want a 307 StatusError", err)
+	}
+}
+
+func TestEncryptPayloadLimit(t *testing.T) {
+	browser := newTestBrowser(t)
+	sub := browser.subscription("https://push.example")
+
+	body, err := Encrypt(sub.P256dh, sub.Auth, bytes.Repeat([]byte("a"), MaxPayloadSize))
+	if err != nil {
+		t.Fatalf("Encrypt(%d bytes): %v", MaxPayloadSize, err)
+	}
+	if len(body) != maxBodySize {
+		t.Errorf("largest message is %d bytes, want exactly %d", len(body), maxBodySize)
+	}
+	if got := browser.decrypt(t, body); len(got) != MaxPayloadSize {
+		t.Errorf("decrypted %d bytes, want %d", len(got), MaxPayloadSize)
+	}
+
+	if _, err := Encrypt(sub.P256dh, sub.Auth, bytes.Repeat([]byte("a"), MaxPayloadSize+1)); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("Encrypt(%d bytes) = %v, want ErrPayloadTooLarge", MaxPayloadSize+1, err)
+	}
+}
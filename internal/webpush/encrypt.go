@@ -0,0 +1,143 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// recordSize is the aes128gcm record size. The whole payload is sent as
+	// a single record.
+	recordSize = 4096
+	// maxBodySize is the largest encrypted message push services must accept
+	// (RFC 8291 section 4). It covers the header as well as the record.
+	maxBodySize = 4096
+	// headerSize is the aes128gcm header: salt (16), record size (4), key id
+	// length (1) and the uncompressed P-256 public key (65).
+	headerSize = 16 + 4 + 1 + 65
+	// MaxPayloadSize is the largest plaintext that keeps the message within
+	// maxBodySize after the header, the padding delimiter and the GCM tag:
+	// 3993 bytes.
+	MaxPayloadSize = maxBodySize - headerSize - 1 - 16
+)
+
+// ErrPayloadTooLarge is returned by Encrypt for plaintexts over
+// MaxPayloadSize.
+var ErrPayloadTooLarge = errors.New("push payload is too large")
+
+// Encrypt encrypts plaintext for a push subscription using the aes128gcm
+// content coding (RFC 8188) with keys derived as described in RFC 8291.
+// p256dh and auth are the subscription's base64url keys.
+func Encrypt(p256dh, auth string, plaintext []byte) ([]byte, error) {
+	if len(plaintext) > MaxPayloadSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds %d bytes", ErrPayloadTooLarge, len(plaintext), MaxPayloadSize)
+	}
+
+	uaPublic, authSecret, err := decodeKeys(p256dh, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublicBytes := asPrivate.PublicKey().Bytes()
+
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	// RFC 8291 section 3.4: combine the ECDH secret with the auth secret
+	keyInfo := "WebPush: info\x00" + string(uaPublic.Bytes()) + string(asPublicBytes)
+	ikm, err := hkdf.Key(sha256.New, ecdhSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	// RFC 8188 section 2.2: content encryption key and nonce
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single, final record: the plaintext followed by the 0x02 delimiter
+	record := make([]byte, 0, len(plaintext)+1)
+	record = append(record, plaintext...)
+	record = append(record, 0x02)
+
+	// Header: salt (16) | record size (4) | key id length (1) | key id
+	header := make([]byte, 0, 16+4+1+len(asPublicBytes))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublicBytes)))
+	header = append(header, asPublicBytes...)
+
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// ValidateKeys reports whether p256dh and auth are usable subscription keys,
+// so malformed subscriptions can be rejected when they are registered.
+func ValidateKeys(p256dh, auth string) error {
+	_, _, err := decodeKeys(p256dh, auth)
+	return err
+}
+
+func decodeKeys(p256dh, auth string) (*ecdh.PublicKey, []byte, error) {
+	uaPublicBytes, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	authSecret, err := decodeBase64URL(auth)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+	if len(authSecret) != 16 {
+		return nil, nil, fmt.Errorf("auth secret must be 16 bytes, got %d", len(authSecret))
+	}
+
+	return uaPublic, authSecret, nil
+}
+
+// decodeBase64URL accepts base64url with or without padding, which is how
+// browsers serialize PushSubscription keys.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,110 @@
+package webpush
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// VAPIDKeys is an application server key pair (RFC 8292). Both halves are
+// shared in unpadded base64url: the public key as an uncompressed P-256 point,
+// which browsers pass as applicationServerKey, and the private key as the raw
+// 32-byte scalar.
+type VAPIDKeys struct {
+	PublicKey  string
+	PrivateKey string
+
+	key *ecdsa.PrivateKey
+}
+
+// GenerateVAPIDKeys creates a new VAPID key pair.
+func GenerateVAPIDKeys() (*VAPIDKeys, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate VAPID key: %w", err)
+	}
+	return newVAPIDKeys(key)
+}
+
+// ParseVAPIDKeys loads a key pair from its base64url private key and checks
+// that the public key, when given, belongs to it.
+func ParseVAPIDKeys(publicKey, privateKey string) (*VAPIDKeys, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key encoding: %w", err)
+	}
+
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	keys, err := newVAPIDKeys(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if publicKey != "" && publicKey != keys.PublicKey {
+		return nil, fmt.Errorf("VAPID public key does not match the private key")
+	}
+
+	return keys, nil
+}
+
+func newVAPIDKeys(key *ecdsa.PrivateKey) (*VAPIDKeys, error) {
+	priv, err := key.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &VAPIDKeys{
+		PublicKey:  base64.RawURLEncoding.EncodeToString(pub),
+		PrivateKey: base64.RawURLEncoding.EncodeToString(priv),
+		key:        key,
+	}, nil
+}
+
+// AuthorizationHeader returns the "vapid t=<jwt>, k=<public key>" header for a
+// push endpoint. The token's audience is the endpoint's origin.
+func (k *VAPIDKeys) AuthorizationHeader(endpoint, subject string, expiry time.Duration) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid push endpoint %q", endpoint)
+	}
+
+	header, _ := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(expiry).Unix(),
+		"sub": subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	r, s, err := ecdsa.Sign(rand.Reader, k.key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+
+	// JWS ES256 signatures are the fixed-width concatenation r || s
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	token := signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+	return "vapid t=" + token + ", k=" + k.PublicKey, nil
+}